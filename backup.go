@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// backupMode selects how the previous version of a file is kept when it is replaced
+type backupMode string
+
+// Backup modes for --backup
+const (
+	backupNone      backupMode = ""
+	backupSingle    backupMode = "bak"
+	backupTimestamp backupMode = "timestamp"
+)
+
+// backupSuffix ends the name of every backup file
+const backupSuffix = ".bak"
+
+// backupTimeLayout is the timestamp in the names of timestamped backups
+const backupTimeLayout = "20060102-150405"
+
+func (b *backupMode) String() string {
+	if b == nil {
+		return ""
+	}
+	return string(*b)
+}
+
+func (b *backupMode) Set(value string) error {
+	switch value {
+	case "true", string(backupSingle):
+		*b = backupSingle
+	case "false":
+		*b = backupNone
+	case string(backupTimestamp):
+		*b = backupTimestamp
+	default:
+		return fmt.Errorf("expected %s or %s", backupSingle, backupTimestamp)
+	}
+	return nil
+}
+
+// IsBoolFlag lets --backup be given without a value
+func (b *backupMode) IsBoolFlag() bool {
+	return true
+}
+
+// backupPath returns the name of the backup of filename: filename.bak, or
+// filename.20060102-150405.bak for timestamped backups
+func backupPath(filename string, mode backupMode, now time.Time) string {
+	if mode == backupTimestamp {
+		return filename + "." + now.Format(backupTimeLayout) + backupSuffix
+	}
+	return filename + backupSuffix
+}
+
+// backupFile copies the current contents of filename to its backup before it is replaced.
+// Nothing is done when mode is backupNone or the file does not exist yet.
+func backupFile(filename string, mode backupMode, now time.Time) error {
+	if mode == backupNone {
+		return nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read file for backup: %w", err)
+	}
+
+	if err := writeFileAtomic(backupPath(filename, mode, now), data); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	return nil
+}
+
+// latestBackup returns the most recently written backup of filename, whether filename.bak or a
+// timestamped backup
+func latestBackup(filename string) (string, error) {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var latest string
+	var latestTime time.Time
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !isBackupName(entry.Name(), base) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return "", fmt.Errorf("failed to read backup %s: %w", entry.Name(), err)
+		}
+		if latest == "" || info.ModTime().After(latestTime) {
+			latest, latestTime = filepath.Join(dir, entry.Name()), info.ModTime()
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("no backup of %s found; backups are written with --backup", filename)
+	}
+	return latest, nil
+}
+
+// isBackupName reports whether name is a backup of the file named base
+func isBackupName(name, base string) bool {
+	if name == base+backupSuffix {
+		return true
+	}
+	stamp, ok := strings.CutPrefix(name, base+".")
+	if !ok {
+		return false
+	}
+	stamp, ok = strings.CutSuffix(stamp, backupSuffix)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(backupTimeLayout, stamp)
+	return err == nil
+}
+
+// restoreBackup replaces filename with its most recent backup and returns the backup's name.
+// The backup itself is kept.
+func restoreBackup(filename string) (string, error) {
+	// Validate filename to prevent path traversal
+	if err := validateFilePath(filename); err != nil {
+		return "", fmt.Errorf("invalid file path: %w", err)
+	}
+
+	backup, err := latestBackup(filename)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(backup)
+	if err != nil {
+		return "", fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	if err := writeFileAtomic(filename, data); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
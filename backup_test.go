@@ -0,0 +1,224 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackupModeFlag(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    backupMode
+		wantErr bool
+	}{
+		{"true", backupSingle, false},
+		{"bak", backupSingle, false},
+		{"timestamp", backupTimestamp, false},
+		{"false", backupNone, false},
+		{"daily", backupNone, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var mode backupMode
+			err := mode.Set(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if mode != tt.want {
+				t.Errorf("Set(%q) = %q, want %q", tt.value, mode, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupFile(t *testing.T) {
+	now := time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		mode       backupMode
+		wantBackup string
+	}{
+		{"single", backupSingle, ".env.bak"},
+		{"timestamp", backupTimestamp, ".env.20260314-150926.bak"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			envFile := filepath.Join(tmpDir, ".env")
+			if err := os.WriteFile(envFile, []byte("KEY=old\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := backupFile(envFile, tt.mode, now); err != nil {
+				t.Fatalf("backupFile() error = %v", err)
+			}
+			if err := writeFileAtomic(envFile, []byte("KEY=new\n")); err != nil {
+				t.Fatalf("writeFileAtomic() error = %v", err)
+			}
+
+			backup, err := os.ReadFile(filepath.Join(tmpDir, tt.wantBackup))
+			if err != nil {
+				t.Fatalf("Failed to read backup: %v", err)
+			}
+			if string(backup) != "KEY=old\n" {
+				t.Errorf("backup = %q, want the previous contents", backup)
+			}
+		})
+	}
+}
+
+func TestWriteOutputFileBackupNewFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	envFile := filepath.Join(tmpDir, ".env")
+
+	err := writeOutputFile(envFile, formatDotenv, map[string]string{"KEY": "value"}, outputOptions{Backup: backupSingle})
+	if err != nil {
+		t.Fatalf("writeOutputFile() error = %v", err)
+	}
+
+	if _, err := os.Stat(envFile + backupSuffix); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup when there was no previous file, stat error = %v", err)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	envFile := filepath.Join(tmpDir, ".env")
+	if err := os.WriteFile(envFile, []byte("KEY=old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeOutputFile(envFile, formatDotenv, map[string]string{"KEY": "new"}, outputOptions{}); err != nil {
+		t.Fatalf("writeOutputFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "KEY=new\n" {
+		t.Errorf("file = %q, want %q", data, "KEY=new\n")
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("Expected only .env in the directory, found %s", strings.Join(names, ", "))
+	}
+}
+
+func TestWriteFileAtomicSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "shared.env")
+	link := filepath.Join(tmpDir, ".env")
+	if err := os.WriteFile(target, []byte("KEY=old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(link, []byte("KEY=new\n")); err != nil {
+		t.Fatalf("writeFileAtomic() error = %v", err)
+	}
+
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Error("Expected the symlink to be kept")
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "KEY=new\n" {
+		t.Errorf("target = %q, want %q", data, "KEY=new\n")
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	envFile := filepath.Join(tmpDir, ".env")
+
+	files := map[string]string{
+		".env":                     "CURRENT=1\n",
+		".env.bak":                 "OLDEST=1\n",
+		".env.20260101-120000.bak": "NEWEST=1\n",
+		".env.20250101-120000.bak": "OLDER=1\n",
+		".env.local.bak":           "NOT_A_BACKUP=1\n",
+		".env.not-a-timestamp.bak": "NOT_A_BACKUP=1\n",
+		".envrc.bak":               "NOT_A_BACKUP=1\n",
+	}
+	modTimes := map[string]time.Time{
+		".env.bak":                 time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		".env.20260101-120000.bak": time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+		".env.20250101-120000.bak": time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		".env.local.bak":           time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		".env.not-a-timestamp.bak": time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		".envrc.bak":               time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if modTime, ok := modTimes[name]; ok {
+			if err := os.Chtimes(path, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	restored, err := restoreBackup(envFile)
+	if err != nil {
+		t.Fatalf("restoreBackup() error = %v", err)
+	}
+	if want := filepath.Join(tmpDir, ".env.20260101-120000.bak"); restored != want {
+		t.Errorf("restoreBackup() restored %s, want %s", restored, want)
+	}
+
+	data, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "NEWEST=1\n" {
+		t.Errorf(".env = %q, want the newest backup", data)
+	}
+
+	// The backup is kept
+	if _, err := os.Stat(restored); err != nil {
+		t.Errorf("Expected the backup to be kept: %v", err)
+	}
+}
+
+func TestRestoreBackupNone(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("KEY=value\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := restoreBackup(envFile)
+	if err == nil || !strings.Contains(err.Error(), "no backup") {
+		t.Errorf("restoreBackup() error = %v, want a no backup error", err)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Exit codes returned by check mode
+const (
+	exitInSync  = 0
+	exitError   = 1
+	exitDrift   = 2
+	exitMissing = 3
+	exitAuth    = 4
+)
+
+// CheckReport is the result of comparing a local .env file with the remote values
+type CheckReport struct {
+	Status      string            `json:"status"`
+	Provider    string            `json:"provider"`
+	Differences []CheckDifference `json:"differences"`
+	Missing     []string          `json:"missing"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// CheckDifference is a single drifted key in a CheckReport
+type CheckDifference struct {
+	Key      string `json:"key"`
+	Path     string `json:"path"`
+	LocalSet bool   `json:"localSet"`
+	Local    string `json:"local"`
+	Remote   string `json:"remote"`
+}
+
+// checkParameters compares the local .env file with the provider's values without prompting
+// or writing anything, and returns a report together with the exit code for it.
+// Missing remote keys take precedence over drift, and auth failures over both.
+func checkParameters(ctx context.Context, provider SecretProvider, mapFile, prefix string, vars map[string]string, envFile string, concurrency int, showValues bool) (CheckReport, int) {
+	report := CheckReport{
+		Provider:    provider.Name(),
+		Differences: []CheckDifference{},
+		Missing:     []string{},
+	}
+
+	fail := func(err error) (CheckReport, int) {
+		report.Error = err.Error()
+		if isAuthError(err) {
+			report.Status = "auth-error"
+			return report, exitAuth
+		}
+		report.Status = "error"
+		return report, exitError
+	}
+
+	paramMap, specs, provider, err := resolveParameterMap(ctx, provider, mapFile, prefix, vars)
+	if err != nil {
+		return fail(fmt.Errorf("failed to load parameter map: %w", err))
+	}
+	// The name can depend on the paths in the resolved map
+	report.Provider = provider.Name()
+
+	localEnvVars, err := readEnvFile(envFile)
+	if err != nil {
+		return fail(fmt.Errorf("failed to read .env file: %w", err))
+	}
+
+	remoteEnvVars, err := fetchParameters(ctx, provider, paramMap, concurrency)
+	if err != nil {
+		return fail(fmt.Errorf("failed to fetch %s parameters: %w", provider.Name(), err))
+	}
+	// A key with a default is only missing when a pull would fail for it
+	applyDefaults(remoteEnvVars, specs)
+
+	for _, diff := range findDifferences(localEnvVars, remoteEnvVars, paramMap) {
+		_, localSet := localEnvVars[diff.Key]
+		local := ""
+		if localSet {
+			local = displayValue(diff.LocalVal, showValues)
+		}
+		report.Differences = append(report.Differences, CheckDifference{
+			Key:      diff.Key,
+			Path:     diff.RemotePath,
+			LocalSet: localSet,
+			Local:    local,
+			Remote:   displayValue(diff.RemoteVal, showValues),
+		})
+	}
+
+	for _, envKey := range sortedKeys(paramMap) {
+		if _, exists := remoteEnvVars[envKey]; !exists {
+			report.Missing = append(report.Missing, envKey)
+		}
+	}
+
+	switch {
+	case len(report.Missing) > 0:
+		report.Status = "missing"
+		return report, exitMissing
+	case len(report.Differences) > 0:
+		report.Status = "drift"
+		return report, exitDrift
+	default:
+		report.Status = "in-sync"
+		return report, exitInSync
+	}
+}
+
+// writeCheckReport writes a check report in the given format ("text" or "json")
+func writeCheckReport(w io.Writer, report CheckReport, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	switch report.Status {
+	case "in-sync":
+		_, err := fmt.Fprintf(w, "✓ All values are in sync with %s.\n", report.Provider)
+		return err
+	case "error", "auth-error":
+		_, err := fmt.Fprintf(w, "✗ Check failed: %s\n", report.Error)
+		return err
+	}
+
+	for _, diff := range report.Differences {
+		local := diff.Local
+		if !diff.LocalSet {
+			local = "(not set)"
+		}
+		if _, err := fmt.Fprintf(w, "DRIFT    %s (%s)\n   Local:  %s\n   %-8s%s\n", diff.Key, diff.Path, local, report.Provider+":", diff.Remote); err != nil {
+			return err
+		}
+	}
+	for _, envKey := range report.Missing {
+		if _, err := fmt.Fprintf(w, "MISSING  %s (not found in %s)\n", envKey, report.Provider); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "✗ %d parameter(s) differ and %d parameter(s) are missing in %s\n", len(report.Differences), len(report.Missing), report.Provider)
+	return err
+}
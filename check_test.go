@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCheckFixtures writes a mapping file and a .env file for check mode tests
+func writeCheckFixtures(t *testing.T, envContent string) (string, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	mapFile := filepath.Join(tmpDir, "map.json")
+	envFile := filepath.Join(tmpDir, ".env")
+
+	mapContent := `{
+		"DB_PASSWORD": "/myapp/dev/db-password",
+		"API_KEY": "/myapp/dev/api-key"
+	}`
+	if err := os.WriteFile(mapFile, []byte(mapContent), 0644); err != nil {
+		t.Fatalf("Failed to create map file: %v", err)
+	}
+	if err := os.WriteFile(envFile, []byte(envContent), 0644); err != nil {
+		t.Fatalf("Failed to create .env file: %v", err)
+	}
+	return mapFile, envFile
+}
+
+func TestCheckParameters(t *testing.T) {
+	remote := map[string]string{
+		"/myapp/dev/db-password": "secret123",
+		"/myapp/dev/api-key":     "my-api-key",
+	}
+
+	tests := []struct {
+		name       string
+		envContent string
+		remote     map[string]string
+		getErr     error
+		wantStatus string
+		wantCode   int
+	}{
+		{"In sync", "DB_PASSWORD=secret123\nAPI_KEY=my-api-key\n", remote, nil, "in-sync", exitInSync},
+		{"Value drift", "DB_PASSWORD=old\nAPI_KEY=my-api-key\n", remote, nil, "drift", exitDrift},
+		{"Key missing locally", "DB_PASSWORD=secret123\n", remote, nil, "drift", exitDrift},
+		{"Key missing remotely", "DB_PASSWORD=secret123\nAPI_KEY=my-api-key\n", map[string]string{"/myapp/dev/db-password": "secret123"}, nil, "missing", exitMissing},
+		{"Auth error", "DB_PASSWORD=secret123\n", remote, &authError{errors.New("denied")}, "auth-error", exitAuth},
+		{"Other error", "DB_PASSWORD=secret123\n", remote, errors.New("boom"), "error", exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapFile, envFile := writeCheckFixtures(t, tt.envContent)
+			values := make(map[string]string)
+			for k, v := range tt.remote {
+				values[k] = v
+			}
+			provider := newMemProvider(values)
+			if tt.getErr != nil {
+				provider.getErrs["/myapp/dev/api-key"] = tt.getErr
+			}
+
+			report, code := checkParameters(context.Background(), provider, mapFile, "", nil, envFile, 1, false)
+			if report.Status != tt.wantStatus || code != tt.wantCode {
+				t.Errorf("checkParameters() = (%q, %d), want (%q, %d)", report.Status, code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCheckParametersMasksValues(t *testing.T) {
+	mapFile, envFile := writeCheckFixtures(t, "DB_PASSWORD=old-secret\nAPI_KEY=my-api-key\n")
+	provider := newMemProvider(map[string]string{
+		"/myapp/dev/db-password": "new-secret",
+		"/myapp/dev/api-key":     "my-api-key",
+	})
+
+	report, _ := checkParameters(context.Background(), provider, mapFile, "", nil, envFile, 1, false)
+
+	var buf bytes.Buffer
+	if err := writeCheckReport(&buf, report, "json"); err != nil {
+		t.Fatalf("writeCheckReport() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "old-secret") || strings.Contains(buf.String(), "new-secret") {
+		t.Errorf("Expected values to be masked, got %s", buf.String())
+	}
+
+	var decoded CheckReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Report is not valid JSON: %v", err)
+	}
+	if decoded.Status != "drift" || len(decoded.Differences) != 1 || decoded.Differences[0].Key != "DB_PASSWORD" {
+		t.Errorf("Unexpected decoded report: %+v", decoded)
+	}
+}
+
+func TestWriteCheckReportText(t *testing.T) {
+	report := CheckReport{
+		Status:   "missing",
+		Provider: "SSM",
+		Differences: []CheckDifference{
+			{Key: "DB_PASSWORD", Path: "/myapp/dev/db-password", LocalSet: false, Remote: "(masked)"},
+		},
+		Missing: []string{"API_KEY"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeCheckReport(&buf, report, "text"); err != nil {
+		t.Fatalf("writeCheckReport() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"DRIFT    DB_PASSWORD", "(not set)", "MISSING  API_KEY"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected text report to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestCheckParametersAppliesDefaults(t *testing.T) {
+	mapFile := filepath.Join(t.TempDir(), "map.json")
+	mapContent := `{
+		"API_KEY": "/myapp/dev/api-key",
+		"DB_PORT": {"path": "/myapp/dev/db-port", "default": 5432}
+	}`
+	if err := os.WriteFile(mapFile, []byte(mapContent), 0644); err != nil {
+		t.Fatalf("Failed to create map file: %v", err)
+	}
+	provider := newMemProvider(map[string]string{"/myapp/dev/api-key": "my-api-key"})
+
+	tests := []struct {
+		name       string
+		envContent string
+		wantStatus string
+		wantCode   int
+	}{
+		{"Default in .env", "API_KEY=my-api-key\nDB_PORT=5432\n", "in-sync", exitInSync},
+		{"Other value in .env", "API_KEY=my-api-key\nDB_PORT=6543\n", "drift", exitDrift},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envFile := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(envFile, []byte(tt.envContent), 0644); err != nil {
+				t.Fatalf("Failed to create .env file: %v", err)
+			}
+
+			report, code := checkParameters(context.Background(), provider, mapFile, "", nil, envFile, 1, false)
+			if report.Status != tt.wantStatus || code != tt.wantCode || len(report.Missing) != 0 {
+				t.Errorf("checkParameters() = (%q, %d, missing %v), want (%q, %d)", report.Status, code, report.Missing, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCheckParametersNamesMixedAWSProvider(t *testing.T) {
+	mapFile := filepath.Join(t.TempDir(), "map.json")
+	mapContent := `{
+		"API_KEY": "/myapp/dev/api-key",
+		"DB_PASSWORD": "sm:myapp/dev/db#password"
+	}`
+	if err := os.WriteFile(mapFile, []byte(mapContent), 0644); err != nil {
+		t.Fatalf("Failed to create map file: %v", err)
+	}
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("API_KEY=my-api-key\n"), 0644); err != nil {
+		t.Fatalf("Failed to create .env file: %v", err)
+	}
+	provider := &awsProvider{
+		ssm: &ssmProvider{client: newFakeSSM(map[string]string{"/myapp/dev/api-key": "my-api-key"})},
+		sm:  &secretsManagerProvider{client: newFakeSecretsManager(map[string]string{"myapp/dev/db": `{"user":"app"}`})},
+	}
+
+	report, code := checkParameters(context.Background(), provider, mapFile, "", nil, envFile, 1, false)
+	if report.Status != "missing" || code != exitMissing {
+		t.Fatalf("checkParameters() = (%q, %d), want (missing, %d)", report.Status, code, exitMissing)
+	}
+	if report.Provider != "SSM and Secrets Manager" {
+		t.Errorf("report.Provider = %q, want SSM and Secrets Manager", report.Provider)
+	}
+
+	var buf bytes.Buffer
+	if err := writeCheckReport(&buf, report, "text"); err != nil {
+		t.Fatalf("writeCheckReport() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "not found in SSM and Secrets Manager") {
+		t.Errorf("Unexpected report:\n%s", buf.String())
+	}
+}
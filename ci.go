@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CI output formats
+const (
+	formatGitHub = "github"
+	formatGitLab = "gitlab"
+)
+
+// githubEnvVar names the file GitHub Actions reads environment variables for later steps from
+const githubEnvVar = "GITHUB_ENV"
+
+// renderGitHubEnv renders the GitHub Actions environment file format. Single-line values are
+// written as KEY=value and multi-line values with the heredoc syntax, using a random delimiter
+// that does not occur in the value.
+func renderGitHubEnv(envVars map[string]string, opts outputOptions) ([]byte, error) {
+	var b bytes.Buffer
+	for _, key := range sortedKeys(envVars) {
+		value := envVars[key]
+		if !strings.ContainsAny(value, "\r\n") {
+			fmt.Fprintf(&b, "%s=%s\n", key, value)
+			continue
+		}
+
+		delimiter, err := githubDelimiter(value)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "%s<<%s\n%s\n%s\n", key, delimiter, value, delimiter)
+	}
+	return b.Bytes(), nil
+}
+
+// githubDelimiter returns a random heredoc delimiter that does not occur in value
+func githubDelimiter(value string) (string, error) {
+	for {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate delimiter: %w", err)
+		}
+		delimiter := "ghadelimiter_" + hex.EncodeToString(buf)
+		if !strings.Contains(value, delimiter) {
+			return delimiter, nil
+		}
+	}
+}
+
+// githubCommandEscaper encodes workflow command data, which the runner decodes before use
+var githubCommandEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// writeGitHubMasks writes an ::add-mask:: workflow command for every value so the runner
+// redacts it from the logs. Multi-line values are masked line by line, as the runner requires,
+// and each line is escaped so the runner decodes it back to the value itself.
+func writeGitHubMasks(w io.Writer, envVars map[string]string) error {
+	for _, key := range sortedKeys(envVars) {
+		for _, line := range strings.Split(envVars[key], "\n") {
+			line = strings.TrimSuffix(line, "\r")
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "::add-mask::%s\n", githubCommandEscaper.Replace(line)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// renderGitLabDotenv renders a GitLab CI dotenv report (artifacts:reports:dotenv). GitLab reads
+// values literally and does not support multi-line values.
+func renderGitLabDotenv(envVars map[string]string, opts outputOptions) ([]byte, error) {
+	var b bytes.Buffer
+	for _, key := range sortedKeys(envVars) {
+		value := envVars[key]
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("GitLab dotenv reports cannot contain the multi-line value of %s", key)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", key, value)
+	}
+	return b.Bytes(), nil
+}
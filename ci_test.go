@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRenderGitHubEnv(t *testing.T) {
+	envVars := map[string]string{
+		"API_KEY": "my-api-key",
+		"CERT":    "-----BEGIN-----\nabc\n-----END-----",
+	}
+
+	data, err := renderGitHubEnv(envVars, outputOptions{})
+	if err != nil {
+		t.Fatalf("renderGitHubEnv() error = %v", err)
+	}
+
+	pattern := regexp.MustCompile(`^API_KEY=my-api-key\nCERT<<(ghadelimiter_[0-9a-f]{32})\n-----BEGIN-----\nabc\n-----END-----\n(ghadelimiter_[0-9a-f]{32})\n$`)
+	match := pattern.FindStringSubmatch(string(data))
+	if match == nil {
+		t.Fatalf("Unexpected GitHub env output:\n%s", data)
+	}
+	if match[1] != match[2] {
+		t.Errorf("Expected matching heredoc delimiters, got %q and %q", match[1], match[2])
+	}
+}
+
+func TestWriteGitHubMasks(t *testing.T) {
+	envVars := map[string]string{
+		"API_KEY": "my-api-key",
+		"CERT":    "line1\r\nline2\n",
+		"EMPTY":   "",
+		"TOKEN":   "abc%25def%0Aghi\rjkl",
+	}
+
+	var out bytes.Buffer
+	if err := writeGitHubMasks(&out, envVars); err != nil {
+		t.Fatalf("writeGitHubMasks() error = %v", err)
+	}
+
+	want := "::add-mask::my-api-key\n::add-mask::line1\n::add-mask::line2\n::add-mask::abc%2525def%250Aghi%0Djkl\n"
+	if out.String() != want {
+		t.Errorf("Unexpected mask commands:\ngot:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestWriteOutputFileGitHubAppends(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), "github_env")
+	if err := os.WriteFile(envFile, []byte("EXISTING=1\n"), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	err := writeOutputFile(envFile, formatGitHub, map[string]string{"API_KEY": "my-api-key"}, outputOptions{Append: true})
+	if err != nil {
+		t.Fatalf("writeOutputFile() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(envFile)
+	if string(content) != "EXISTING=1\nAPI_KEY=my-api-key\n" {
+		t.Errorf("Expected value to be appended, got %q", content)
+	}
+}
+
+func TestRenderGitLabDotenv(t *testing.T) {
+	data, err := renderGitLabDotenv(map[string]string{"API_KEY": "my-api-key", "DB_PASSWORD": `p@ss "word"`}, outputOptions{})
+	if err != nil {
+		t.Fatalf("renderGitLabDotenv() error = %v", err)
+	}
+	if string(data) != "API_KEY=my-api-key\nDB_PASSWORD=p@ss \"word\"\n" {
+		t.Errorf("Unexpected GitLab dotenv output: %q", data)
+	}
+
+	_, err = renderGitLabDotenv(map[string]string{"CERT": "line1\nline2"}, outputOptions{})
+	if err == nil || !strings.Contains(err.Error(), "CERT") {
+		t.Errorf("Expected error naming CERT, got %v", err)
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// dotenvEntry is a single KEY=value assignment in a .env file
+type dotenvEntry struct {
+	Key   string
+	Value string
+	// Line is the line the assignment starts on, counting from 1
+	Line int
+	// ValueStart and ValueEnd are the byte offsets of the value as written, including quotes
+	ValueStart int
+	ValueEnd   int
+}
+
+// dotenvError is a syntax error in a .env file
+type dotenvError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *dotenvError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// dotenvParser parses the contents of a .env file
+type dotenvParser struct {
+	data      []byte
+	pos       int
+	line      int
+	lineStart int
+
+	// vars holds the values assigned so far, for ${VAR} interpolation
+	vars map[string]string
+	// lookup resolves ${VAR} references to variables not assigned earlier in the file
+	lookup func(name string) (string, bool)
+}
+
+// parseDotenv parses .env data into its assignments, in file order. It accepts:
+//   - blank lines and # comments, including comments after a value
+//   - an optional "export " before the key
+//   - unquoted values, which end at the line end or at a " #" comment and are trimmed
+//   - double-quoted values, which can span lines and support \n, \r, \t, \", \\ and \$ escapes
+//   - single-quoted values, which can span lines and are taken literally
+//
+// ${VAR} in unquoted and double-quoted values is replaced by the value assigned to VAR earlier
+// in the file, or else by lookup(VAR), or else by the empty string.
+func parseDotenv(data []byte, lookup func(name string) (string, bool)) ([]dotenvEntry, error) {
+	p := &dotenvParser{data: data, line: 1, vars: make(map[string]string), lookup: lookup}
+
+	var entries []dotenvEntry
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return entries, nil
+		}
+
+		switch p.peek() {
+		case '\r', '\n':
+			p.advance()
+			continue
+		case '#':
+			p.skipToLineEnd()
+			continue
+		}
+
+		entry, err := p.parseEntry()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+		p.vars[entry.Key] = entry.Value
+	}
+}
+
+// parseEntry parses a single assignment and the rest of its line
+func (p *dotenvParser) parseEntry() (dotenvEntry, error) {
+	entry := dotenvEntry{Line: p.line}
+
+	key := p.parseKey()
+	if key == "export" && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipSpaces()
+		key = p.parseKey()
+	}
+	if key == "" {
+		return entry, p.errorf("expected a variable name")
+	}
+	entry.Key = key
+
+	p.skipSpaces()
+	if p.peek() != '=' {
+		return entry, p.errorf("expected '=' after %s", key)
+	}
+	p.advance()
+	p.skipSpaces()
+
+	entry.ValueStart = p.pos
+	var err error
+	switch p.peek() {
+	case '"':
+		entry.Value, err = p.parseDoubleQuoted()
+	case '\'':
+		entry.Value, err = p.parseSingleQuoted()
+	default:
+		entry.Value, err = p.parseUnquoted()
+	}
+	if err != nil {
+		return entry, err
+	}
+	entry.ValueEnd = p.pos
+
+	// Only a comment may follow the value
+	p.skipSpaces()
+	if p.peek() == '#' {
+		p.skipToLineEnd()
+	}
+	if p.peek() == '\r' {
+		p.advance()
+	}
+	if !p.eof() && p.peek() != '\n' {
+		return entry, p.errorf("unexpected character %q after the value of %s", p.peek(), key)
+	}
+
+	return entry, nil
+}
+
+// parseKey reads a variable name
+func (p *dotenvParser) parseKey() string {
+	start := p.pos
+	for !p.eof() && isDotenvKeyChar(p.peek()) {
+		p.advance()
+	}
+	return string(p.data[start:p.pos])
+}
+
+// parseDoubleQuoted reads a double-quoted value, handling escapes and ${VAR} references
+func (p *dotenvParser) parseDoubleQuoted() (string, error) {
+	openErr := p.errorf("unterminated double-quoted value")
+	p.advance()
+
+	var value []byte
+	for {
+		if p.eof() {
+			return "", openErr
+		}
+
+		c := p.peek()
+		switch {
+		case c == '"':
+			p.advance()
+			return string(value), nil
+		case c == '\\' && p.pos+1 < len(p.data):
+			p.advance()
+			switch escaped := p.peek(); escaped {
+			case 'n':
+				value = append(value, '\n')
+			case 'r':
+				value = append(value, '\r')
+			case 't':
+				value = append(value, '\t')
+			case '"', '\\', '$':
+				value = append(value, escaped)
+			default:
+				value = append(value, '\\', escaped)
+			}
+			p.advance()
+		case c == '$' && p.peekAt(1) == '{':
+			expanded, err := p.parseReference()
+			if err != nil {
+				return "", err
+			}
+			value = append(value, expanded...)
+		default:
+			value = append(value, c)
+			p.advance()
+		}
+	}
+}
+
+// parseSingleQuoted reads a single-quoted value literally
+func (p *dotenvParser) parseSingleQuoted() (string, error) {
+	openErr := p.errorf("unterminated single-quoted value")
+	p.advance()
+
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		p.advance()
+	}
+	if p.eof() {
+		return "", openErr
+	}
+
+	value := string(p.data[start:p.pos])
+	p.advance()
+	return value, nil
+}
+
+// parseUnquoted reads an unquoted value up to the line end or a comment, trimming trailing
+// whitespace, and leaves the position just after the last character of the value
+func (p *dotenvParser) parseUnquoted() (string, error) {
+	var value []byte
+	end, valueEnd := p.pos, 0
+	for !p.eof() {
+		c := p.peek()
+		if c == '\n' || (c == '#' && (p.pos == 0 || isDotenvSpace(p.data[p.pos-1]) || p.data[p.pos-1] == '=')) {
+			break
+		}
+
+		if c == '$' && p.peekAt(1) == '{' {
+			expanded, err := p.parseReference()
+			if err != nil {
+				return "", err
+			}
+			value = append(value, expanded...)
+		} else {
+			value = append(value, c)
+			p.advance()
+		}
+
+		if !isDotenvSpace(c) && c != '\r' {
+			end, valueEnd = p.pos, len(value)
+		}
+	}
+
+	// Step back over trailing whitespace so it is not part of the value
+	p.rewind(end)
+	return string(value[:valueEnd]), nil
+}
+
+// parseReference reads a ${VAR} reference and returns its value
+func (p *dotenvParser) parseReference() (string, error) {
+	refErr := p.errorf("unterminated variable reference")
+	p.advance()
+	p.advance()
+
+	name := p.parseKey()
+	if p.peek() != '}' {
+		if p.eof() || p.peek() == '\n' {
+			return "", refErr
+		}
+		return "", p.errorf("invalid character %q in variable reference", p.peek())
+	}
+	if name == "" {
+		return "", p.errorf("empty variable reference")
+	}
+	p.advance()
+
+	if value, ok := p.vars[name]; ok {
+		return value, nil
+	}
+	if p.lookup != nil {
+		if value, ok := p.lookup(name); ok {
+			return value, nil
+		}
+	}
+	return "", nil
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+// peek returns the current byte, or 0 at the end of the data
+func (p *dotenvParser) peek() byte {
+	return p.peekAt(0)
+}
+
+// peekAt returns the byte offset bytes ahead, or 0 past the end of the data
+func (p *dotenvParser) peekAt(offset int) byte {
+	if p.pos+offset >= len(p.data) {
+		return 0
+	}
+	return p.data[p.pos+offset]
+}
+
+// advance moves past the current byte, keeping track of lines
+func (p *dotenvParser) advance() {
+	if p.data[p.pos] == '\n' {
+		p.line++
+		p.lineStart = p.pos + 1
+	}
+	p.pos++
+}
+
+// rewind moves back to an earlier position on the current line
+func (p *dotenvParser) rewind(pos int) {
+	p.pos = pos
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for !p.eof() && isDotenvSpace(p.peek()) {
+		p.advance()
+	}
+}
+
+// skipToLineEnd moves to the newline ending the current line, or the end of the data
+func (p *dotenvParser) skipToLineEnd() {
+	for !p.eof() && p.peek() != '\n' {
+		p.advance()
+	}
+}
+
+// errorf returns a dotenvError at the current position
+func (p *dotenvParser) errorf(format string, args ...any) error {
+	return &dotenvError{
+		Line:   p.line,
+		Column: utf8.RuneCount(p.data[p.lineStart:p.pos]) + 1,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func isDotenvSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func isDotenvKeyChar(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_' || c == '.' || c == '-'
+}
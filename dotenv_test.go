@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	content := "# Database\n" +
+		"export DB_HOST=localhost\n" +
+		"DB_PORT = 5432 # default port\n" +
+		"DB_URL=postgres://${DB_HOST}:${DB_PORT}/app\n" +
+		"HASH_IN_VALUE=abc#def\n" +
+		"EMPTY=\n" +
+		"EMPTY_WITH_COMMENT= # nothing here\n" +
+		"DOUBLE=\"line1\\nline2 \\\"quoted\\\" \\${LITERAL} ${DB_HOST}\" # comment\n" +
+		"SINGLE='no \\n escapes or ${DB_HOST}'\n" +
+		"CERT=\"-----BEGIN CERTIFICATE-----\n" +
+		"MIIB\n" +
+		"-----END CERTIFICATE-----\"\n" +
+		"SINGLE_MULTILINE='a\n" +
+		"b'\n" +
+		"FROM_ENV=${HOME_DIR}/bin\n" +
+		"UNSET=${NOT_SET}x\n" +
+		"WINDOWS=crlf\r\n" +
+		"export=not a prefix\n"
+
+	lookup := func(name string) (string, bool) {
+		if name == "HOME_DIR" {
+			return "/home/user", true
+		}
+		return "", false
+	}
+
+	entries, err := parseDotenv([]byte(content), lookup)
+	if err != nil {
+		t.Fatalf("parseDotenv() error = %v", err)
+	}
+
+	expected := []struct {
+		key   string
+		value string
+		line  int
+	}{
+		{"DB_HOST", "localhost", 2},
+		{"DB_PORT", "5432", 3},
+		{"DB_URL", "postgres://localhost:5432/app", 4},
+		{"HASH_IN_VALUE", "abc#def", 5},
+		{"EMPTY", "", 6},
+		{"EMPTY_WITH_COMMENT", "", 7},
+		{"DOUBLE", "line1\nline2 \"quoted\" ${LITERAL} localhost", 8},
+		{"SINGLE", "no \\n escapes or ${DB_HOST}", 9},
+		{"CERT", "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----", 10},
+		{"SINGLE_MULTILINE", "a\nb", 13},
+		{"FROM_ENV", "/home/user/bin", 15},
+		{"UNSET", "x", 16},
+		{"WINDOWS", "crlf", 17},
+		{"export", "not a prefix", 18},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d: %+v", len(expected), len(entries), entries)
+	}
+	for i, want := range expected {
+		got := entries[i]
+		if got.Key != want.key || got.Value != want.value || got.Line != want.line {
+			t.Errorf("Entry %d: expected %s=%q on line %d, got %s=%q on line %d", i, want.key, want.value, want.line, got.Key, got.Value, got.Line)
+		}
+	}
+}
+
+func TestParseDotenvValueOffsets(t *testing.T) {
+	content := "A=plain  # comment\nB=\"quoted\"\nC='single'\n"
+
+	entries, err := parseDotenv([]byte(content), nil)
+	if err != nil {
+		t.Fatalf("parseDotenv() error = %v", err)
+	}
+
+	for i, want := range []string{"plain", `"quoted"`, "'single'"} {
+		if raw := content[entries[i].ValueStart:entries[i].ValueEnd]; raw != want {
+			t.Errorf("Entry %d: expected raw value %q, got %q", i, want, raw)
+		}
+	}
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+	}{
+		{"missing equals", "VALID=1\nINVALID_LINE\n", 2, 13},
+		{"missing key", "VALID=1\n=value\n", 2, 1},
+		{"unterminated double quote", "A=1\nB=\"never closed\nC=3\n", 2, 3},
+		{"unterminated single quote", "A='never closed\n", 1, 3},
+		{"text after quoted value", "A=\"x\" y\n", 1, 7},
+		{"unterminated reference", "A=${B\n", 1, 3},
+		{"invalid reference", "A=\"${B C}\"\n", 1, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseDotenv([]byte(tt.content), nil)
+			var syntaxErr *dotenvError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Expected dotenvError, got %v", err)
+			}
+			if syntaxErr.Line != tt.line || syntaxErr.Column != tt.column {
+				t.Errorf("Expected error at line %d, column %d, got %v", tt.line, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestDotenvRoundTrip(t *testing.T) {
+	envVars := map[string]string{
+		"CERT":      "-----BEGIN-----\nabc\n-----END-----",
+		"DOLLAR":    "pa$$${word}",
+		"HASH":      "#not-a-comment",
+		"QUOTES":    `it's "quoted"`,
+		"BACKSLASH": `C:\path\n`,
+		"SPACES":    "  padded  ",
+		"PLAIN":     "simple",
+		"EMPTY":     "",
+	}
+
+	for _, quotes := range []bool{false, true} {
+		data, err := renderDotenv(envVars, outputOptions{Quotes: quotes})
+		if err != nil {
+			t.Fatalf("renderDotenv() error = %v", err)
+		}
+
+		entries, err := parseDotenv(data, nil)
+		if err != nil {
+			t.Fatalf("parseDotenv() error = %v for:\n%s", err, data)
+		}
+		if len(entries) != len(envVars) {
+			t.Errorf("Expected %d entries, got %d", len(envVars), len(entries))
+		}
+		for _, entry := range entries {
+			if entry.Value != envVars[entry.Key] {
+				t.Errorf("Quotes %v: for key %s, expected %q, got %q", quotes, entry.Key, envVars[entry.Key], entry.Value)
+			}
+		}
+	}
+}
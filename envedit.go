@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+)
+
+// updateEnvFile sets the given keys in an existing .env file without regenerating it. The value
+// of each key already in the file is replaced where it stands, keeping any "export " prefix and
+// trailing comment; keys that are new are appended at the end. Everything else, including
+// comments, blank lines, ordering and the quoting of other values, is left byte-for-byte intact.
+func updateEnvFile(filename string, updates map[string]string, opts outputOptions) error {
+	// Validate filename to prevent path traversal
+	if err := validateFilePath(filename); err != nil {
+		return fmt.Errorf("invalid file path: %w", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return writeOutputFile(filename, formatDotenv, updates, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	updated, err := applyEnvUpdates(data, updates, opts.Quotes)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	return writeOutputData(filename, updated, opts)
+}
+
+// applyEnvUpdates returns a copy of the .env data with the given keys set. When a key is
+// assigned more than once, the last assignment, which is the one that takes effect, is replaced.
+func applyEnvUpdates(data []byte, updates map[string]string, alwaysQuote bool) ([]byte, error) {
+	entries, err := parseDotenv(data, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	lastEntry := make(map[string]dotenvEntry, len(entries))
+	for _, entry := range entries {
+		lastEntry[entry.Key] = entry
+	}
+
+	// Replace existing values from the end of the file so earlier offsets stay valid
+	var replace []dotenvEntry
+	var appendKeys []string
+	for _, key := range sortedKeys(updates) {
+		if entry, exists := lastEntry[key]; exists {
+			replace = append(replace, entry)
+		} else {
+			appendKeys = append(appendKeys, key)
+		}
+	}
+	sort.Slice(replace, func(i, j int) bool {
+		return replace[i].ValueStart > replace[j].ValueStart
+	})
+
+	updated := bytes.Clone(data)
+	for _, entry := range replace {
+		value := []byte(formatDotenvValue(updates[entry.Key], alwaysQuote))
+		updated = append(updated[:entry.ValueStart], append(value, updated[entry.ValueEnd:]...)...)
+	}
+
+	if len(appendKeys) > 0 {
+		// Match the file's line endings
+		newline := "\n"
+		if bytes.Contains(data, []byte("\r\n")) {
+			newline = "\r\n"
+		}
+		if len(updated) > 0 && updated[len(updated)-1] != '\n' {
+			updated = append(updated, newline...)
+		}
+		for _, key := range appendKeys {
+			updated = append(updated, fmt.Sprintf("%s=%s%s", key, formatDotenvValue(updates[key], alwaysQuote), newline)...)
+		}
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyEnvUpdates(t *testing.T) {
+	content := "# Database settings\n" +
+		"export DB_HOST=localhost # local only\n" +
+		"\n" +
+		"DB_PASSWORD = 'old password'   # rotated monthly\n" +
+		"API_KEY=\"unchanged\"\n" +
+		"CERT=\"-----BEGIN-----\n" +
+		"old\n" +
+		"-----END-----\"\n" +
+		"TOKEN=first\n" +
+		"TOKEN=second # wins\n" +
+		"LAST=no newline"
+
+	updates := map[string]string{
+		"DB_HOST":     "db.internal",
+		"DB_PASSWORD": "new#password",
+		"CERT":        "-----BEGIN-----\nnew\n-----END-----",
+		"TOKEN":       "third",
+		"NEW_B":       "b",
+		"NEW_A":       "a value",
+	}
+
+	got, err := applyEnvUpdates([]byte(content), updates, false)
+	if err != nil {
+		t.Fatalf("applyEnvUpdates() error = %v", err)
+	}
+
+	want := "# Database settings\n" +
+		"export DB_HOST=db.internal # local only\n" +
+		"\n" +
+		"DB_PASSWORD = \"new#password\"   # rotated monthly\n" +
+		"API_KEY=\"unchanged\"\n" +
+		"CERT=\"-----BEGIN-----\\nnew\\n-----END-----\"\n" +
+		"TOKEN=first\n" +
+		"TOKEN=third # wins\n" +
+		"LAST=no newline\n" +
+		"NEW_A=\"a value\"\n" +
+		"NEW_B=b\n"
+	if string(got) != want {
+		t.Errorf("applyEnvUpdates() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The result must read back with the updated values
+	vars, err := parseDotenv(got, nil)
+	if err != nil {
+		t.Fatalf("parseDotenv() error = %v", err)
+	}
+	values := make(map[string]string)
+	for _, entry := range vars {
+		values[entry.Key] = entry.Value
+	}
+	for key, value := range updates {
+		if values[key] != value {
+			t.Errorf("%s = %q after update, want %q", key, values[key], value)
+		}
+	}
+}
+
+func TestApplyEnvUpdatesKeepsCRLF(t *testing.T) {
+	content := "# comment\r\nKEY=old\r\nOTHER=x\r\n"
+
+	got, err := applyEnvUpdates([]byte(content), map[string]string{"KEY": "new", "ADDED": "y"}, true)
+	if err != nil {
+		t.Fatalf("applyEnvUpdates() error = %v", err)
+	}
+
+	want := "# comment\r\nKEY=\"new\"\r\nOTHER=x\r\nADDED=\"y\"\r\n"
+	if string(got) != want {
+		t.Errorf("applyEnvUpdates() = %q, want %q", got, want)
+	}
+}
+
+func TestApplyEnvUpdatesNoChanges(t *testing.T) {
+	content := "# untouched\nA=1\n\n  B = '2'  # note\n"
+
+	got, err := applyEnvUpdates([]byte(content), nil, false)
+	if err != nil {
+		t.Fatalf("applyEnvUpdates() error = %v", err)
+	}
+	if string(got) != content {
+		t.Errorf("applyEnvUpdates() = %q, want the input unchanged", got)
+	}
+}
+
+func TestUpdateEnvFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("existing file", func(t *testing.T) {
+		envFile := filepath.Join(tmpDir, ".env")
+		if err := os.WriteFile(envFile, []byte("# keep me\nA=1\nB=2\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := updateEnvFile(envFile, map[string]string{"B": "3"}, outputOptions{}); err != nil {
+			t.Fatalf("updateEnvFile() error = %v", err)
+		}
+
+		data, err := os.ReadFile(envFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "# keep me\nA=1\nB=3\n"; string(data) != want {
+			t.Errorf("file = %q, want %q", data, want)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		envFile := filepath.Join(tmpDir, "new.env")
+
+		if err := updateEnvFile(envFile, map[string]string{"B": "2", "A": "1"}, outputOptions{}); err != nil {
+			t.Fatalf("updateEnvFile() error = %v", err)
+		}
+
+		data, err := os.ReadFile(envFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "A=1\nB=2\n"; string(data) != want {
+			t.Errorf("file = %q, want %q", data, want)
+		}
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		envFile := filepath.Join(tmpDir, "bad.env")
+		content := "A=\"unterminated\n"
+		if err := os.WriteFile(envFile, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := updateEnvFile(envFile, map[string]string{"A": "1"}, outputOptions{}); err == nil {
+			t.Error("updateEnvFile() expected an error for an invalid file")
+		}
+
+		data, err := os.ReadFile(envFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("file = %q, want it left unchanged", data)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+)
+
+// mergeEnv overlays envVars onto a base environment in KEY=value form. Existing keys are
+// replaced in place and new keys are appended in sorted order.
+func mergeEnv(base []string, envVars map[string]string) []string {
+	merged := make([]string, 0, len(base)+len(envVars))
+	seen := make(map[string]bool, len(envVars))
+
+	for _, entry := range base {
+		key, _, _ := strings.Cut(entry, "=")
+		if value, ok := envVars[key]; ok {
+			if seen[key] {
+				// Drop duplicate entries for an overridden key
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, key+"="+value)
+			continue
+		}
+		merged = append(merged, entry)
+	}
+
+	for _, key := range sortedKeys(envVars) {
+		if !seen[key] {
+			merged = append(merged, key+"="+envVars[key])
+		}
+	}
+
+	return merged
+}
+
+// runCommand runs args as a child process with the given environment, forwarding signals
+// received by EnvChanter to the child, and returns the child's exit code
+func runCommand(args []string, env []string) (int, error) {
+	if len(args) == 0 {
+		return 1, errors.New("no command given")
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Start listening before the child exists so no signal is missed in between
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return 1, err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				// The child may already have exited; there is nothing useful to do on failure
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return 1, err
+		}
+	}
+
+	return exitCode(cmd.ProcessState), nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestMergeEnv(t *testing.T) {
+	base := []string{"PATH=/usr/bin", "DB_PASSWORD=stale", "HOME=/home/dev", "DB_PASSWORD=duplicate"}
+	envVars := map[string]string{
+		"DB_PASSWORD": "secret123",
+		"API_KEY":     "my-api-key",
+	}
+
+	merged := mergeEnv(base, envVars)
+
+	expected := []string{"PATH=/usr/bin", "DB_PASSWORD=secret123", "HOME=/home/dev", "API_KEY=my-api-key"}
+	if strings.Join(merged, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("mergeEnv() = %v, expected %v", merged, expected)
+	}
+}
+
+func TestRunCommandPassesEnvAndExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	tmpDir := t.TempDir()
+	outFile := filepath.Join(tmpDir, "out")
+
+	env := mergeEnv(os.Environ(), map[string]string{"DB_PASSWORD": "secret123", "OUT_FILE": outFile})
+	code, err := runCommand([]string{"sh", "-c", `printf %s "$DB_PASSWORD" > "$OUT_FILE"; exit 3`}, env)
+	if err != nil {
+		t.Fatalf("runCommand() error = %v", err)
+	}
+	if code != 3 {
+		t.Errorf("Expected exit code 3, got %d", code)
+	}
+
+	content, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("Failed to read child output: %v", err)
+	}
+	if string(content) != "secret123" {
+		t.Errorf("Expected child to see DB_PASSWORD=secret123, got %q", content)
+	}
+}
+
+func TestRunCommandReportsSignalExit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX signals")
+	}
+
+	code, err := runCommand([]string{"sh", "-c", "kill -TERM $$"}, os.Environ())
+	if err != nil {
+		t.Fatalf("runCommand() error = %v", err)
+	}
+	if code != 128+15 {
+		t.Errorf("Expected exit code 143, got %d", code)
+	}
+}
+
+func TestRunCommandForwardsSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX signals")
+	}
+
+	tmpDir := t.TempDir()
+	readyFile := filepath.Join(tmpDir, "ready")
+	outFile := filepath.Join(tmpDir, "out")
+
+	// The child records the SIGTERM it receives, stops its sleep and lets the signal terminate it
+	script := `trap 'echo received > "$OUT_FILE"; kill $!; trap - TERM; kill -TERM $$' TERM
+: > "$READY_FILE"
+sleep 5 & wait $!`
+	env := mergeEnv(os.Environ(), map[string]string{"READY_FILE": readyFile, "OUT_FILE": outFile})
+
+	type result struct {
+		code int
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		code, err := runCommand([]string{"sh", "-c", script}, env)
+		results <- result{code, err}
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(readyFile); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Child did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to find own process: %v", err)
+	}
+	if err := self.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("Failed to send SIGTERM: %v", err)
+	}
+
+	res := <-results
+	if res.err != nil {
+		t.Fatalf("runCommand() error = %v", res.err)
+	}
+	if res.code != 128+15 {
+		t.Errorf("Expected exit code 143, got %d", res.code)
+	}
+
+	content, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("Child did not receive SIGTERM: %v", err)
+	}
+	if strings.TrimSpace(string(content)) != "received" {
+		t.Errorf("Unexpected child output %q", content)
+	}
+}
+
+func TestRunCommandMissingBinary(t *testing.T) {
+	_, err := runCommand([]string{filepath.Join(t.TempDir(), "does-not-exist")}, os.Environ())
+	if err == nil {
+		t.Error("Expected error for missing command, got nil")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// forwardedSignals are the signals relayed from EnvChanter to the child in exec mode.
+// Windows delivers Ctrl+C to the whole console, so this mainly stops EnvChanter exiting first.
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+// exitCode returns the child's exit code
+func exitCode(state *os.ProcessState) int {
+	return state.ExitCode()
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Reserved mapping keys for inheritance. "$extends" names the base maps to start from and
+// "$remove" lists keys inherited from them to drop. Like prefixMapKey they cannot be env var
+// names, so they never clash with an entry.
+const (
+	extendsMapKey = "$extends"
+	removeMapKey  = "$remove"
+)
+
+// loadMapEntries reads a mapping file and merges it over the maps it extends. Base maps are
+// applied in order, then the removals, then the file's own entries. Relative base paths are
+// resolved against the directory of the file that names them. stack holds the absolute paths
+// of the files currently being loaded and is used to detect cycles. onRead, if not nil, is
+// called with the contents of every file before it is decoded.
+func loadMapEntries(filename string, stack []string, onRead func(filename string, data []byte) error) (map[string]any, error) {
+	// Validate filename to prevent path traversal
+	if err := validateFilePath(filename); err != nil {
+		return nil, fmt.Errorf("invalid file path: %w", err)
+	}
+
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file path: %w", err)
+	}
+	for i, loading := range stack {
+		if loading == absPath {
+			cycle := append(stack[i:len(stack):len(stack)], absPath)
+			return nil, fmt.Errorf("extends cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if onRead != nil {
+		if err := onRead(filename, data); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := decodeMapFile(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	bases, err := mapFileList(entries, extendsMapKey)
+	if err != nil {
+		return nil, err
+	}
+	removals, err := mapFileList(entries, removeMapKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(removals) > 0 && len(bases) == 0 {
+		return nil, fmt.Errorf("%q can only be used together with %q", removeMapKey, extendsMapKey)
+	}
+
+	merged := make(map[string]any, len(entries))
+	for _, base := range bases {
+		if err := validateFilePath(base); err != nil {
+			return nil, fmt.Errorf("invalid %s path %q: %w", extendsMapKey, base, err)
+		}
+		if !filepath.IsAbs(base) {
+			base = filepath.Join(filepath.Dir(filename), base)
+		}
+
+		baseEntries, err := loadMapEntries(base, append(stack[:len(stack):len(stack)], absPath), onRead)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", base, err)
+		}
+		maps.Copy(merged, baseEntries)
+	}
+
+	for _, envKey := range removals {
+		if _, exists := merged[envKey]; !exists {
+			return nil, fmt.Errorf("cannot remove %s: it is not defined in an extended map", envKey)
+		}
+		delete(merged, envKey)
+	}
+
+	maps.Copy(merged, entries)
+	return merged, nil
+}
+
+// mapFileList removes a reserved key from the entries and returns its value, which may be a
+// single string or a list of strings
+func mapFileList(entries map[string]any, key string) ([]string, error) {
+	value, exists := entries[key]
+	if !exists {
+		return nil, nil
+	}
+	delete(entries, key)
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%q must be a string or a list of strings", key)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	}
+
+	return nil, fmt.Errorf("%q must be a string or a list of strings", key)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeMapFiles writes mapping files relative to dir
+func writeMapFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+}
+
+func TestLoadParameterMapExtends(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeMapFiles(t, tmpDir, map[string]string{
+		"maps/base.yaml": `
+DB_PASSWORD: /myapp/shared/db-password
+API_KEY: /myapp/shared/api-key
+LEGACY_TOKEN: /myapp/shared/legacy-token
+`,
+		"maps/prod.json": `{
+			"$extends": "base.yaml",
+			"$remove": ["LEGACY_TOKEN"],
+			"API_KEY": "/myapp/prod/api-key",
+			"DATABASE_URL": {"path": "/myapp/prod/database-url", "type": "url"}
+		}`,
+	})
+
+	paramMap, specs, err := loadParameterMap(filepath.Join(tmpDir, "maps", "prod.json"), &ssmProvider{}, nil)
+	if err != nil {
+		t.Fatalf("loadParameterMap() error = %v", err)
+	}
+
+	expected := ParameterMap{
+		"DB_PASSWORD":  "/myapp/shared/db-password",
+		"API_KEY":      "/myapp/prod/api-key",
+		"DATABASE_URL": "/myapp/prod/database-url",
+	}
+	if len(paramMap) != len(expected) {
+		t.Errorf("Expected %d parameters, got %v", len(expected), paramMap)
+	}
+	for key, want := range expected {
+		if paramMap[key] != want {
+			t.Errorf("For key %s, expected %q, got %q", key, want, paramMap[key])
+		}
+	}
+	if specs["DATABASE_URL"].Type != "url" {
+		t.Errorf("Expected spec for DATABASE_URL to be kept, got %+v", specs["DATABASE_URL"])
+	}
+}
+
+func TestLoadParameterMapExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"a.json": `{"$extends": "b.json", "API_KEY": "/myapp/dev/api-key"}`,
+				"b.json": `{"$extends": ["a.json"]}`,
+			},
+			wantErr: "extends cycle detected",
+		},
+		{
+			name: "self",
+			files: map[string]string{
+				"a.json": `{"$extends": "a.json"}`,
+			},
+			wantErr: "extends cycle detected",
+		},
+		{
+			name: "path traversal",
+			files: map[string]string{
+				"a.json": `{"$extends": "../base.json"}`,
+			},
+			wantErr: "path traversal detected",
+		},
+		{
+			name: "remove unknown key",
+			files: map[string]string{
+				"a.json":    `{"$extends": "base.json", "$remove": "MISSING"}`,
+				"base.json": `{"API_KEY": "/myapp/dev/api-key"}`,
+			},
+			wantErr: "cannot remove MISSING",
+		},
+		{
+			name: "$remove without $extends",
+			files: map[string]string{
+				"a.json": `{"$remove": "API_KEY", "API_KEY": "/myapp/dev/api-key"}`,
+			},
+			wantErr: "can only be used together with",
+		},
+		{
+			name: "invalid extends value",
+			files: map[string]string{
+				"a.json": `{"$extends": 1}`,
+			},
+			wantErr: "must be a string or a list of strings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeMapFiles(t, tmpDir, tt.files)
+
+			_, _, err := loadParameterMap(filepath.Join(tmpDir, "a.json"), &ssmProvider{}, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadParameterMapExtendsDiamond(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeMapFiles(t, tmpDir, map[string]string{
+		"common.json": `{"API_KEY": "/myapp/shared/api-key"}`,
+		"db.json":     `{"$extends": "common.json", "DB_PASSWORD": "/myapp/shared/db-password"}`,
+		"cache.json":  `{"$extends": "common.json", "REDIS_URL": "/myapp/shared/redis-url"}`,
+		"app.json":    `{"$extends": ["db.json", "cache.json"]}`,
+	})
+
+	paramMap, _, err := loadParameterMap(filepath.Join(tmpDir, "app.json"), &ssmProvider{}, nil)
+	if err != nil {
+		t.Fatalf("loadParameterMap() error = %v", err)
+	}
+	if len(paramMap) != 3 {
+		t.Errorf("Expected 3 parameters, got %v", paramMap)
+	}
+}
+
+func TestLoadParameterMapExtendsAndRemoveAreVariableNames(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeMapFiles(t, tmpDir, map[string]string{
+		"a.json": `{"extends": "/myapp/dev/extends", "remove": "/myapp/dev/remove"}`,
+	})
+
+	paramMap, _, err := loadParameterMap(filepath.Join(tmpDir, "a.json"), &ssmProvider{}, nil)
+	if err != nil {
+		t.Fatalf("loadParameterMap() error = %v", err)
+	}
+	if paramMap["extends"] != "/myapp/dev/extends" || paramMap["remove"] != "/myapp/dev/remove" {
+		t.Errorf("loadParameterMap() = %v", paramMap)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// formatK8sSecret renders a Kubernetes Secret manifest
+const formatK8sSecret = "k8s-secret"
+
+// defaultK8sSecretType is the Secret type used when --k8s-type is not given
+const defaultK8sSecretType = "Opaque"
+
+// k8sSecret is a Kubernetes Secret manifest. Field order matches kubectl's output.
+type k8sSecret struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   k8sObjectMeta     `yaml:"metadata"`
+	Type       string            `yaml:"type"`
+	Data       map[string]string `yaml:"data"`
+}
+
+// k8sObjectMeta is the metadata of a Kubernetes object
+type k8sObjectMeta struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+// labelFlags collects repeated --k8s-label key=value flags
+type labelFlags map[string]string
+
+func (l labelFlags) String() string {
+	pairs := make([]string, 0, len(l))
+	for _, key := range sortedKeys(l) {
+		pairs = append(pairs, key+"="+l[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (l labelFlags) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected key=value")
+	}
+	if err := validateK8sLabelKey(key); err != nil {
+		return fmt.Errorf("invalid label key %q: %w", key, err)
+	}
+	if val != "" {
+		if err := validateK8sLabelSegment(val); err != nil {
+			return fmt.Errorf("invalid label value %q: %w", val, err)
+		}
+	}
+	l[key] = val
+	return nil
+}
+
+// validateK8sLabelKey validates a label key: a name, optionally preceded by a DNS subdomain
+// prefix and '/', as in app.kubernetes.io/name
+func validateK8sLabelKey(key string) error {
+	name := key
+	if prefix, rest, ok := strings.Cut(key, "/"); ok {
+		if err := validateK8sName(prefix, 253); err != nil {
+			return fmt.Errorf("invalid prefix: %w", err)
+		}
+		name = rest
+	}
+	if name == "" {
+		return fmt.Errorf("empty name")
+	}
+	return validateK8sLabelSegment(name)
+}
+
+// validateK8sLabelSegment validates a label name or a non-empty label value: up to 63
+// alphanumeric characters, '-', '_' and '.', starting and ending with an alphanumeric character
+func validateK8sLabelSegment(segment string) error {
+	if len(segment) > 63 {
+		return fmt.Errorf("exceeds maximum length of 63 characters")
+	}
+	for _, char := range segment {
+		if !((char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') || char == '-' || char == '_' || char == '.') {
+			return fmt.Errorf("contains invalid character: %c (only alphanumeric, '-', '_' and '.' allowed)", char)
+		}
+	}
+	if first, last := segment[0], segment[len(segment)-1]; strings.ContainsRune("-_.", rune(first)) || strings.ContainsRune("-_.", rune(last)) {
+		return fmt.Errorf("must start and end with an alphanumeric character")
+	}
+	return nil
+}
+
+// validateK8sName validates a Kubernetes object name or namespace: lowercase alphanumeric
+// characters, '-' and '.', starting and ending with an alphanumeric character
+func validateK8sName(name string, maxLen int) error {
+	if name == "" {
+		return fmt.Errorf("empty name")
+	}
+	if len(name) > maxLen {
+		return fmt.Errorf("name exceeds maximum length of %d characters", maxLen)
+	}
+	for _, char := range name {
+		if !((char >= 'a' && char <= 'z') || (char >= '0' && char <= '9') || char == '-' || char == '.') {
+			return fmt.Errorf("name contains invalid character: %c (only lowercase alphanumeric, '-' and '.' allowed)", char)
+		}
+	}
+	if first, last := name[0], name[len(name)-1]; first == '-' || first == '.' || last == '-' || last == '.' {
+		return fmt.Errorf("name must start and end with an alphanumeric character")
+	}
+	return nil
+}
+
+// renderK8sSecret renders a Kubernetes Secret manifest with base64-encoded data, which can be
+// passed to kubectl apply or used as input to kubeseal
+func renderK8sSecret(envVars map[string]string, opts outputOptions) ([]byte, error) {
+	if err := validateK8sName(opts.K8sName, 253); err != nil {
+		return nil, fmt.Errorf("invalid Kubernetes Secret name: %w", err)
+	}
+	if opts.K8sNamespace != "" {
+		if err := validateK8sName(opts.K8sNamespace, 63); err != nil {
+			return nil, fmt.Errorf("invalid Kubernetes namespace: %w", err)
+		}
+	}
+
+	secretType := opts.K8sType
+	if secretType == "" {
+		secretType = defaultK8sSecretType
+	}
+
+	data := make(map[string]string, len(envVars))
+	for key, value := range envVars {
+		data[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+
+	manifest, err := yaml.Marshal(k8sSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: k8sObjectMeta{
+			Name:      opts.K8sName,
+			Namespace: opts.K8sNamespace,
+			Labels:    opts.K8sLabels,
+		},
+		Type: secretType,
+		Data: data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Kubernetes Secret: %w", err)
+	}
+
+	return manifest, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderK8sSecret(t *testing.T) {
+	envVars := map[string]string{
+		"DB_PASSWORD": "secret123",
+		"CERT":        "line1\nline2",
+	}
+	opts := outputOptions{
+		K8sName:      "app-secrets",
+		K8sNamespace: "prod",
+		K8sLabels:    map[string]string{"app.kubernetes.io/name": "myapp"},
+		K8sType:      defaultK8sSecretType,
+	}
+
+	data, err := renderK8sSecret(envVars, opts)
+	if err != nil {
+		t.Fatalf("renderK8sSecret() error = %v", err)
+	}
+	if !strings.HasPrefix(string(data), "apiVersion: v1\nkind: Secret\n") {
+		t.Errorf("Expected manifest to start with apiVersion and kind, got:\n%s", data)
+	}
+
+	var secret k8sSecret
+	if err := yaml.Unmarshal(data, &secret); err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+
+	if secret.Metadata.Name != "app-secrets" || secret.Metadata.Namespace != "prod" || secret.Type != "Opaque" {
+		t.Errorf("Unexpected manifest metadata: %+v, type %q", secret.Metadata, secret.Type)
+	}
+	if secret.Metadata.Labels["app.kubernetes.io/name"] != "myapp" {
+		t.Errorf("Expected label app.kubernetes.io/name=myapp, got %v", secret.Metadata.Labels)
+	}
+	for key, want := range envVars {
+		got, err := base64.StdEncoding.DecodeString(secret.Data[key])
+		if err != nil || string(got) != want {
+			t.Errorf("For key %s, expected %q, got %q (err %v)", key, want, got, err)
+		}
+	}
+}
+
+func TestRenderK8sSecretInvalidName(t *testing.T) {
+	tests := []outputOptions{
+		{},
+		{K8sName: "App_Secrets"},
+		{K8sName: "-app"},
+		{K8sName: "app-secrets", K8sNamespace: "Prod"},
+	}
+
+	for _, opts := range tests {
+		if _, err := renderK8sSecret(map[string]string{"KEY": "value"}, opts); err == nil {
+			t.Errorf("Expected error for name %q and namespace %q, got nil", opts.K8sName, opts.K8sNamespace)
+		}
+	}
+}
+
+func TestLabelFlagsSet(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"app=myapp", false},
+		{"app.kubernetes.io/name=myapp", false},
+		{"tier=Back_End.v2", false},
+		{"release=", false},
+		{"app", true},
+		{"=myapp", true},
+		{"example.com/=myapp", true},
+		{"/name=myapp", true},
+		{"Example.com/name=myapp", true},
+		{"a/b/c=myapp", true},
+		{"-app=myapp", true},
+		{"app=my app", true},
+		{"app=myapp-", true},
+		{"app=" + strings.Repeat("a", 64), true},
+		{strings.Repeat("a", 64) + "=myapp", true},
+	}
+
+	for _, tt := range tests {
+		err := labelFlags{}.Set(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Set(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+	}
+}
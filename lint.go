@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// lintIssue is a problem found in a mapping or .env file
+type lintIssue struct {
+	File string
+	// Line is the line the problem is on, or 0 when it concerns the file as a whole
+	Line int
+	Msg  string
+}
+
+func (i lintIssue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s", i.File, i.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Msg)
+}
+
+// lintFiles checks a mapping file, and the .env file unless envFile is empty, for mistakes that
+// loading silently resolves: duplicate keys, several env vars mapped to the same remote path and
+// .env keys that are not in the map. The returned error means a file could not be checked at all.
+func lintFiles(mapFile, envFile string, vars map[string]string) ([]lintIssue, error) {
+	var issues []lintIssue
+
+	// Check the map and every base map it extends. YAML and TOML decoding already rejects
+	// duplicate keys; JSON keeps the last one.
+	findDuplicates := func(filename string, data []byte) error {
+		if format := strings.ToLower(filepath.Ext(filename)); format == ".yaml" || format == ".yml" || format == ".toml" {
+			return nil
+		}
+		duplicates, err := findJSONDuplicateKeys(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		for _, dup := range duplicates {
+			issues = append(issues, lintIssue{File: filename, Line: dup.Line, Msg: dup.String()})
+		}
+		return nil
+	}
+
+	mapEntries, err := loadMapEntries(mapFile, nil, findDuplicates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", mapFile, err)
+	}
+	paramMap, _, err := parseMapEntries(mapEntries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: failed to parse mapping: %w", mapFile, err)
+	}
+	paramMap, err = interpolateParameterMap(paramMap, vars)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameter map: %w", err)
+	}
+
+	// Env vars sharing a remote path overwrite each other on push
+	keysByPath := make(map[string][]string)
+	for _, envKey := range sortedKeys(paramMap) {
+		if envKey == prefixMapKey {
+			continue
+		}
+		keysByPath[paramMap[envKey]] = append(keysByPath[paramMap[envKey]], envKey)
+	}
+	for _, path := range sortedKeys(keysByPath) {
+		if keys := keysByPath[path]; len(keys) > 1 {
+			issues = append(issues, lintIssue{
+				File: mapFile,
+				Msg:  fmt.Sprintf("%s all map to %s", strings.Join(keys, ", "), path),
+			})
+		}
+	}
+
+	if envFile == "" {
+		return issues, nil
+	}
+
+	if err := validateFilePath(envFile); err != nil {
+		return nil, fmt.Errorf("invalid file path: %w", err)
+	}
+	data, err := os.ReadFile(envFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	entries, err := parseDotenv(data, os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", envFile, err)
+	}
+
+	// With a prefix entry the keys come from the remote paths, so any key may be expected
+	_, hasPrefix := paramMap[prefixMapKey]
+
+	firstLine := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		if line, exists := firstLine[entry.Key]; exists {
+			issues = append(issues, lintIssue{
+				File: envFile,
+				Line: entry.Line,
+				Msg:  fmt.Sprintf("duplicate key %s (first defined on line %d, the last value wins)", entry.Key, line),
+			})
+			continue
+		}
+		firstLine[entry.Key] = entry.Line
+
+		if _, mapped := paramMap[entry.Key]; !mapped && !hasPrefix {
+			issues = append(issues, lintIssue{
+				File: envFile,
+				Line: entry.Line,
+				Msg:  fmt.Sprintf("%s is not in %s and is ignored by push and sync", entry.Key, mapFile),
+			})
+		}
+	}
+
+	return issues, nil
+}
+
+// jsonDuplicateKey is a key that appears more than once in the same JSON object
+type jsonDuplicateKey struct {
+	Key string
+	// Object is the key of the enclosing object, or empty for the top-level object
+	Object    string
+	Line      int
+	FirstLine int
+}
+
+func (d jsonDuplicateKey) String() string {
+	if d.Object == "" {
+		return fmt.Sprintf("duplicate key %q (first defined on line %d, the last value wins)", d.Key, d.FirstLine)
+	}
+	return fmt.Sprintf("duplicate key %q in %s (first defined on line %d, the last value wins)", d.Key, d.Object, d.FirstLine)
+}
+
+// jsonFrame tracks an object or array while scanning JSON tokens
+type jsonFrame struct {
+	isObject  bool
+	expectKey bool
+	name      string
+	keyLines  map[string]int
+}
+
+// findJSONDuplicateKeys returns the keys repeated within a JSON object at any depth, which
+// json.Unmarshal silently resolves by keeping the last value
+func findJSONDuplicateKeys(data []byte) ([]jsonDuplicateKey, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	var duplicates []jsonDuplicateKey
+	var stack []*jsonFrame
+	var lastKey string
+
+	// valueDone marks the end of a value, after which an object expects its next key
+	valueDone := func() {
+		if len(stack) > 0 && stack[len(stack)-1].isObject {
+			stack[len(stack)-1].expectKey = true
+		}
+	}
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return duplicates, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var top *jsonFrame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		if key, ok := token.(string); ok && top != nil && top.isObject && top.expectKey {
+			line := lineAt(data, decoder.InputOffset())
+			if first, exists := top.keyLines[key]; exists {
+				duplicates = append(duplicates, jsonDuplicateKey{Key: key, Object: top.name, Line: line, FirstLine: first})
+			} else {
+				top.keyLines[key] = line
+			}
+			top.expectKey = false
+			lastKey = key
+			continue
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			frame := &jsonFrame{isObject: token == json.Delim('{'), expectKey: true, keyLines: make(map[string]int)}
+			if top != nil && top.isObject {
+				frame.name = lastKey
+			}
+			stack = append(stack, frame)
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			valueDone()
+		default:
+			valueDone()
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindJSONDuplicateKeys(t *testing.T) {
+	data := `{
+  "DB_PASSWORD": "/myapp/dev/db-password",
+  "API_KEY": {
+    "path": "/myapp/dev/api-key",
+    "required": true,
+    "path": "/myapp/dev/api-key-v2"
+  },
+  "$extends": ["base.json", "shared.json"],
+  "DB_PASSWORD": "/myapp/dev/db-password-v2"
+}`
+
+	duplicates, err := findJSONDuplicateKeys([]byte(data))
+	if err != nil {
+		t.Fatalf("findJSONDuplicateKeys() error = %v", err)
+	}
+
+	want := []jsonDuplicateKey{
+		{Key: "path", Object: "API_KEY", Line: 6, FirstLine: 4},
+		{Key: "DB_PASSWORD", Line: 9, FirstLine: 2},
+	}
+	if len(duplicates) != len(want) {
+		t.Fatalf("findJSONDuplicateKeys() = %+v, want %+v", duplicates, want)
+	}
+	for i := range want {
+		if duplicates[i] != want[i] {
+			t.Errorf("duplicate %d = %+v, want %+v", i, duplicates[i], want[i])
+		}
+	}
+}
+
+func TestFindJSONDuplicateKeysNone(t *testing.T) {
+	data := `{"A": {"path": "/a"}, "B": {"path": "/b"}, "$extends": ["x", "x"]}`
+
+	duplicates, err := findJSONDuplicateKeys([]byte(data))
+	if err != nil {
+		t.Fatalf("findJSONDuplicateKeys() error = %v", err)
+	}
+	if len(duplicates) != 0 {
+		t.Errorf("findJSONDuplicateKeys() = %+v, want none", duplicates)
+	}
+}
+
+func TestLintFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	mapFile := filepath.Join(tmpDir, "map.json")
+	envFile := filepath.Join(tmpDir, ".env")
+
+	mapContent := `{
+  "DB_PASSWORD": "/myapp/${stage}/db-password",
+  "DATABASE_PASSWORD": "/myapp/${stage}/db-password",
+  "API_KEY": "/myapp/${stage}/api-key",
+  "API_KEY": "/myapp/${stage}/api-key-v2"
+}`
+	envContent := "DB_PASSWORD=secret\n" +
+		"API_KEY=key\n" +
+		"# local only\n" +
+		"DEBUG=true\n" +
+		"API_KEY=other\n"
+	if err := os.WriteFile(mapFile, []byte(mapContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(envFile, []byte(envContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := lintFiles(mapFile, envFile, map[string]string{"stage": "dev"})
+	if err != nil {
+		t.Fatalf("lintFiles() error = %v", err)
+	}
+
+	want := []string{
+		mapFile + `:5: duplicate key "API_KEY" (first defined on line 4, the last value wins)`,
+		mapFile + ": DATABASE_PASSWORD, DB_PASSWORD all map to /myapp/dev/db-password",
+		envFile + ":4: DEBUG is not in " + mapFile + " and is ignored by push and sync",
+		envFile + ":5: duplicate key API_KEY (first defined on line 2, the last value wins)",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("lintFiles() = %v, want %d issues", issues, len(want))
+	}
+	for i := range want {
+		if got := issues[i].String(); got != want[i] {
+			t.Errorf("issue %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestLintFilesClean(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"map.yaml": "DB_PASSWORD: /myapp/dev/db-password\nAPI_KEY: /myapp/dev/api-key\n",
+		".env":     "DB_PASSWORD=secret\nAPI_KEY=key\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	issues, err := lintFiles(filepath.Join(tmpDir, "map.yaml"), filepath.Join(tmpDir, ".env"), nil)
+	if err != nil {
+		t.Fatalf("lintFiles() error = %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("lintFiles() = %v, want no issues", issues)
+	}
+}
+
+func TestLintFilesPrefixAllowsAnyKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	mapFile := filepath.Join(tmpDir, "map.json")
+	envFile := filepath.Join(tmpDir, ".env")
+	if err := os.WriteFile(mapFile, []byte(`{"*": "/myapp/dev/"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(envFile, []byte("ANYTHING=1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := lintFiles(mapFile, envFile, nil)
+	if err != nil {
+		t.Fatalf("lintFiles() error = %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("lintFiles() = %v, want no issues", issues)
+	}
+}
+
+func TestLintFilesYAMLDuplicate(t *testing.T) {
+	mapFile := filepath.Join(t.TempDir(), "map.yaml")
+	if err := os.WriteFile(mapFile, []byte("API_KEY: /a\nAPI_KEY: /b\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := lintFiles(mapFile, "", nil)
+	if err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Errorf("lintFiles() error = %v, want a duplicate key error", err)
+	}
+}
+
+func TestLintFilesChecksExtendedMaps(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeMapFiles(t, tmpDir, map[string]string{
+		"base.json": "{\n  \"API_KEY\": \"/myapp/dev/api-key\",\n  \"API_KEY\": \"/myapp/dev/api-key-v2\"\n}",
+		"map.yaml":  "$extends: base.json\nDB_PASSWORD: /myapp/dev/db-password\n",
+	})
+
+	issues, err := lintFiles(filepath.Join(tmpDir, "map.yaml"), "", nil)
+	if err != nil {
+		t.Fatalf("lintFiles() error = %v", err)
+	}
+
+	want := filepath.Join(tmpDir, "base.json") + `:3: duplicate key "API_KEY" (first defined on line 2, the last value wins)`
+	if len(issues) != 1 || issues[0].String() != want {
+		t.Errorf("lintFiles() = %v, want [%s]", issues, want)
+	}
+}
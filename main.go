@@ -1,1067 +1,1596 @@
-package main
-
-import (
-	"context"
-	"encoding/json"
-	"errors"
-	"flag"
-	"fmt"
-	"net/http"
-	"os"
-	"sort"
-	"strings"
-
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/ssm"
-)
-
-var version = "dev"
-
-const asciiArt = `
-███████╗███╗   ██╗██╗   ██╗ ██████╗██╗  ██╗ █████╗ ███╗   ██╗████████╗███████╗██████╗ 
-██╔════╝████╗  ██║██║   ██║██╔════╝██║  ██║██╔══██╗████╗  ██║╚══██╔══╝██╔════╝██╔══██╗
-█████╗  ██╔██╗ ██║██║   ██║██║     ███████║███████║██╔██╗ ██║   ██║   █████╗  ██████╔╝
-██╔══╝  ██║╚██╗██║╚██╗ ██╔╝██║     ██╔══██║██╔══██║██║╚██╗██║   ██║   ██╔══╝  ██╔══██╗
-███████╗██║ ╚████║ ╚████╔╝ ╚██████╗██║  ██║██║  ██║██║ ╚████║   ██║   ███████╗██║  ██║
-╚══════╝╚═╝  ╚═══╝  ╚═══╝   ╚═════╝╚═╝  ╚═╝╚═╝  ╚═╝╚═╝  ╚═══╝   ╚═╝   ╚══════╝╚═╝  ╚═╝
-`
-
-type ParameterMap map[string]string
-
-// validateFilePath validates a file path to prevent path traversal attacks
-func validateFilePath(path string) error {
-	if path == "" {
-		return fmt.Errorf("empty file path")
-	}
-
-	// Check for path traversal attempts
-	cleanPath := strings.TrimSpace(path)
-	if strings.Contains(cleanPath, "..") {
-		return fmt.Errorf("path traversal detected")
-	}
-
-	// Check for null bytes
-	if strings.Contains(cleanPath, "\x00") {
-		return fmt.Errorf("null byte in file path")
-	}
-
-	return nil
-}
-
-// validateParameterMap validates the contents of a parameter map
-func validateParameterMap(paramMap ParameterMap) error {
-	if len(paramMap) == 0 {
-		return fmt.Errorf("parameter map is empty")
-	}
-
-	for envKey, ssmPath := range paramMap {
-		// Validate environment variable name
-		if err := validateEnvVarName(envKey); err != nil {
-			return fmt.Errorf("invalid environment variable name %q: %w", envKey, err)
-		}
-
-		// Validate SSM path
-		if err := validateSSMPath(ssmPath); err != nil {
-			return fmt.Errorf("invalid SSM path %q for key %q: %w", ssmPath, envKey, err)
-		}
-	}
-
-	return nil
-}
-
-// validateEnvVarName validates an environment variable name
-func validateEnvVarName(name string) error {
-	if name == "" {
-		return fmt.Errorf("empty environment variable name")
-	}
-
-	// Environment variable names should only contain alphanumeric characters and underscores
-	// and should not start with a digit
-	for i, char := range name {
-		if i == 0 && char >= '0' && char <= '9' {
-			return fmt.Errorf("environment variable name cannot start with a digit")
-		}
-		if !((char >= 'A' && char <= 'Z') || (char >= 'a' && char <= 'z') ||
-			(char >= '0' && char <= '9') || char == '_') {
-			return fmt.Errorf("environment variable name contains invalid character: %c", char)
-		}
-	}
-
-	return nil
-}
-
-// validateSSMPath validates an AWS SSM parameter path
-func validateSSMPath(path string) error {
-	if path == "" {
-		return fmt.Errorf("empty SSM path")
-	}
-
-	// SSM parameter names must start with /
-	if !strings.HasPrefix(path, "/") {
-		return fmt.Errorf("SSM path must start with /")
-	}
-
-	// Check for invalid characters (AWS SSM allows alphanumeric, -, _, ., and /)
-	for _, char := range path {
-		if !((char >= 'A' && char <= 'Z') || (char >= 'a' && char <= 'z') ||
-			(char >= '0' && char <= '9') || char == '-' || char == '_' ||
-			char == '.' || char == '/') {
-			return fmt.Errorf("SSM path contains invalid character: %c", char)
-		}
-	}
-
-	// Check for path traversal attempts
-	if strings.Contains(path, "..") {
-		return fmt.Errorf("path traversal detected in SSM path")
-	}
-
-	// Check length (AWS SSM has a max path length of 2048 characters)
-	if len(path) > 2048 {
-		return fmt.Errorf("SSM path exceeds maximum length of 2048 characters")
-	}
-
-	return nil
-}
-
-// validateAzureSecretName validates an Azure Key Vault secret name
-func validateAzureSecretName(name string) error {
-	if name == "" {
-		return fmt.Errorf("empty secret name")
-	}
-
-	// Azure Key Vault secret names must be 1-127 characters long and contain only alphanumeric characters and hyphens
-	if len(name) > 127 {
-		return fmt.Errorf("secret name exceeds maximum length of 127 characters")
-	}
-
-	for _, char := range name {
-		if !((char >= 'A' && char <= 'Z') || (char >= 'a' && char <= 'z') ||
-			(char >= '0' && char <= '9') || char == '-') {
-			return fmt.Errorf("secret name contains invalid character: %c (only alphanumeric and hyphens allowed)", char)
-		}
-	}
-
-	return nil
-}
-
-// validateAzureParameterMap validates the contents of a parameter map for Azure Key Vault
-func validateAzureParameterMap(paramMap ParameterMap) error {
-	if len(paramMap) == 0 {
-		return fmt.Errorf("parameter map is empty")
-	}
-
-	for envKey, secretName := range paramMap {
-		// Validate environment variable name
-		if err := validateEnvVarName(envKey); err != nil {
-			return fmt.Errorf("invalid environment variable name %q: %w", envKey, err)
-		}
-
-		// Validate Azure secret name
-		if err := validateAzureSecretName(secretName); err != nil {
-			return fmt.Errorf("invalid Azure secret name %q for key %q: %w", secretName, envKey, err)
-		}
-	}
-
-	return nil
-}
-
-// createAzureClient creates an Azure Key Vault client
-func createAzureClient(ctx context.Context, vaultName string) (*azsecrets.Client, error) {
-	// Create default Azure credential (uses managed identity, environment variables, or Azure CLI)
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure credential: %w", err)
-	}
-
-	// Construct vault URL
-	vaultURL := fmt.Sprintf("https://%s.vault.azure.net/", vaultName)
-
-	// Create secrets client
-	client, err := azsecrets.NewClient(vaultURL, cred, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure Key Vault client: %w", err)
-	}
-
-	return client, nil
-}
-
-// checkAzureAuthError checks if an error is an authentication or authorization error
-func checkAzureAuthError(err error) error {
-	var respErr *azcore.ResponseError
-	if errors.As(err, &respErr) {
-		switch respErr.StatusCode {
-		case http.StatusUnauthorized: // 401
-			return fmt.Errorf("Azure authentication failed: no valid credentials available. Please run 'az login' or configure Azure credentials")
-		case http.StatusForbidden: // 403
-			return fmt.Errorf("Azure authorization failed: insufficient permissions to access Key Vault. Ensure you have the required role assigned (e.g., 'Key Vault Secrets User' for read, 'Key Vault Secrets Officer' for write)")
-		}
-	}
-	return nil
-}
-
-// fetchParametersFromAzure retrieves secret values from Azure Key Vault
-func fetchParametersFromAzure(ctx context.Context, client *azsecrets.Client, paramMap ParameterMap) (map[string]string, error) {
-	envVars := make(map[string]string)
-
-	for envKey, secretName := range paramMap {
-		// Get the latest version of the secret (empty version string gets latest)
-		resp, err := client.GetSecret(ctx, secretName, "", nil)
-		if err != nil {
-			// Check for authentication/authorization errors first
-			if authErr := checkAzureAuthError(err); authErr != nil {
-				return nil, authErr
-			}
-
-			// Check if the error is NotFound
-			var respErr *azcore.ResponseError
-			if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
-				fmt.Printf("Warning: secret not found for %s, skipping.\n", envKey)
-				continue
-			}
-
-			// For other errors, fail without exposing the secret name
-			return nil, fmt.Errorf("failed to get secret for %s: %w", envKey, err)
-		}
-
-		if resp.Value != nil {
-			envVars[envKey] = *resp.Value
-		}
-	}
-
-	return envVars, nil
-}
-
-// pushSingleParameterToAzure pushes a single parameter to Azure Key Vault
-func pushSingleParameterToAzure(ctx context.Context, client *azsecrets.Client, key, value, secretName string) error {
-	params := azsecrets.SetSecretParameters{
-		Value: &value,
-	}
-
-	_, err := client.SetSecret(ctx, secretName, params, nil)
-	if err != nil {
-		// Check for authentication/authorization errors
-		if authErr := checkAzureAuthError(err); authErr != nil {
-			return authErr
-		}
-		return fmt.Errorf("failed to set secret: %w", err)
-	}
-
-	return nil
-}
-
-// pushParametersToAzure pushes multiple parameters to Azure Key Vault based on mapping
-func pushParametersToAzure(ctx context.Context, client *azsecrets.Client, envVars map[string]string, paramMap ParameterMap) error {
-	for envKey, secretName := range paramMap {
-		value, exists := envVars[envKey]
-		if !exists {
-			// Skip parameters that don't exist in the .env file
-			continue
-		}
-
-		params := azsecrets.SetSecretParameters{
-			Value: &value,
-		}
-
-		_, err := client.SetSecret(ctx, secretName, params, nil)
-		if err != nil {
-			// Check for authentication/authorization errors
-			if authErr := checkAzureAuthError(err); authErr != nil {
-				return authErr
-			}
-			return fmt.Errorf("failed to set secret %s: %w", envKey, err)
-		}
-	}
-
-	return nil
-}
-
-// syncParametersWithAzure compares local .env with Azure Key Vault values and updates the .env file
-func syncParametersWithAzure(ctx context.Context, client *azsecrets.Client, localEnvVars map[string]string, paramMap ParameterMap, envFile string, force bool, quotes bool) error {
-	// Fetch current values from Azure Key Vault
-	azureEnvVars, err := fetchParametersFromAzure(ctx, client, paramMap)
-	if err != nil {
-		return fmt.Errorf("failed to fetch Azure Key Vault secrets: %w", err)
-	}
-
-	// Compare local and Azure values
-	var differences []Difference
-	for envKey, secretName := range paramMap {
-		localVal, localExists := localEnvVars[envKey]
-		azureVal, azureExists := azureEnvVars[envKey]
-
-		// Check if there's a difference
-		if !localExists {
-			// Local doesn't have this key, but Azure does
-			if azureExists {
-				differences = append(differences, Difference{
-					Key:       envKey,
-					LocalVal:  "",
-					SSMVal:    azureVal,
-					SSMPath:   secretName,
-					ExistsSSM: true,
-				})
-			}
-		} else if !azureExists {
-			// Local has the key but Azure doesn't - skip this
-			continue
-		} else if localVal != azureVal {
-			// Both exist but values differ
-			differences = append(differences, Difference{
-				Key:       envKey,
-				LocalVal:  localVal,
-				SSMVal:    azureVal,
-				SSMPath:   secretName,
-				ExistsSSM: true,
-			})
-		}
-	}
-
-	// If no differences found
-	if len(differences) == 0 {
-		fmt.Println("✓ All values are in sync. No updates needed.")
-		return nil
-	}
-
-	// Sort differences by key for consistent output
-	sort.Slice(differences, func(i, j int) bool {
-		return differences[i].Key < differences[j].Key
-	})
-
-	// Display differences
-	fmt.Printf("\nFound %d secret(s) with differences:\n\n", len(differences))
-	for i, diff := range differences {
-		fmt.Printf("%d. %s\n", i+1, diff.Key)
-		if diff.LocalVal == "" {
-			fmt.Printf("   Local:  (not set)\n")
-		} else {
-			fmt.Printf("   Local:  %s\n", diff.LocalVal)
-		}
-		fmt.Printf("   Azure:  %s\n", diff.SSMVal)
-		fmt.Printf("   Name:   %s\n\n", diff.SSMPath)
-	}
-
-	// Determine which values to update
-	var toUpdate []Difference
-	if force {
-		// Force mode: update all differences
-		toUpdate = differences
-		fmt.Printf("Force mode enabled. Updating all %d secret(s)...\n", len(toUpdate))
-	} else {
-		// Interactive mode: prompt for each difference
-		toUpdate, err = promptForUpdates(differences)
-		if err != nil {
-			return fmt.Errorf("error during prompting: %w", err)
-		}
-	}
-
-	if len(toUpdate) == 0 {
-		fmt.Println("No secrets selected for update.")
-		return nil
-	}
-
-	// Update local env vars with selected Azure values
-	for _, diff := range toUpdate {
-		localEnvVars[diff.Key] = diff.SSMVal
-	}
-
-	// Write updated values to .env file
-	err = writeEnvFile(envFile, localEnvVars, quotes)
-	if err != nil {
-		return fmt.Errorf("failed to write updated .env file: %w", err)
-	}
-
-	fmt.Printf("\n✓ Successfully updated %s with %d secret(s) from Azure Key Vault\n", envFile, len(toUpdate))
-	return nil
-}
-
-// loadParameterMapRaw reads the JSON mapping file without validation
-func loadParameterMapRaw(filename string) (ParameterMap, error) {
-	// Validate filename to prevent path traversal
-	if err := validateFilePath(filename); err != nil {
-		return nil, fmt.Errorf("invalid file path: %w", err)
-	}
-
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
-	}
-
-	var paramMap ParameterMap
-	err = json.Unmarshal(data, &paramMap)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
-	}
-
-	return paramMap, nil
-}
-
-// loadParameterMap reads the JSON mapping file and validates for AWS SSM
-func loadParameterMap(filename string) (ParameterMap, error) {
-	paramMap, err := loadParameterMapRaw(filename)
-	if err != nil {
-		return nil, err
-	}
-
-	// Validate parameter map contents for AWS SSM
-	if err := validateParameterMap(paramMap); err != nil {
-		return nil, fmt.Errorf("invalid parameter map: %w", err)
-	}
-
-	return paramMap, nil
-}
-
-// loadAWSConfig creates an AWS config with optional profile and region
-func loadAWSConfig(ctx context.Context, profile, region string) (aws.Config, error) {
-	var opts []func(*config.LoadOptions) error
-
-	if profile != "" {
-		opts = append(opts, config.WithSharedConfigProfile(profile))
-	}
-
-	if region != "" {
-		opts = append(opts, config.WithRegion(region))
-	}
-
-	cfg, err := config.LoadDefaultConfig(ctx, opts...)
-	if err != nil {
-		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
-	}
-
-	return cfg, nil
-}
-
-// fetchParameters retrieves parameter values from AWS SSM
-func fetchParameters(ctx context.Context, client *ssm.Client, paramMap ParameterMap) (map[string]string, error) {
-	envVars := make(map[string]string)
-
-	for envKey, ssmPath := range paramMap {
-		input := &ssm.GetParameterInput{
-			Name:           &ssmPath,
-			WithDecryption: boolPtr(true),
-		}
-
-		result, err := client.GetParameter(ctx, input)
-		if err != nil {
-			// If the error is ParameterNotFound, log a warning and continue
-			if strings.Contains(err.Error(), "ParameterNotFound") {
-				fmt.Printf("Warning: parameter not found for %s, skipping.\n", envKey)
-				continue
-			}
-			// For other errors, fail without exposing the path
-			return nil, fmt.Errorf("failed to get parameter for %s: %w", envKey, err)
-		}
-
-		if result.Parameter != nil && result.Parameter.Value != nil {
-			envVars[envKey] = *result.Parameter.Value
-		}
-	}
-
-	return envVars, nil
-}
-
-// writeEnvFile writes environment variables to a .env file
-func writeEnvFile(filename string, envVars map[string]string, alwaysQuote bool) error {
-	// Validate filename to prevent path traversal
-	if err := validateFilePath(filename); err != nil {
-		return fmt.Errorf("invalid file path: %w", err)
-	}
-
-	// Create file with restrictive permissions (0600 = owner read/write only)
-	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer file.Close()
-
-	// Sort keys for consistent output
-	keys := make([]string, 0, len(envVars))
-	for key := range envVars {
-		keys = append(keys, key)
-	}
-	sort.Strings(keys)
-
-	// Write each environment variable
-	for _, key := range keys {
-		value := envVars[key]
-		if alwaysQuote {
-			value = fmt.Sprintf("\"%s\"", escapeValue(value))
-		} else if needsQuoting(value) {
-			value = fmt.Sprintf("\"%s\"", escapeValue(value))
-		}
-		_, err := fmt.Fprintf(file, "%s=%s\n", key, value)
-		if err != nil {
-			return fmt.Errorf("failed to write to file: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// needsQuoting checks if a value needs to be quoted
-func needsQuoting(value string) bool {
-	return strings.ContainsAny(value, " \t\n\r\"'\\")
-}
-
-// escapeValue escapes special characters in a value
-func escapeValue(value string) string {
-	value = strings.ReplaceAll(value, "\\", "\\\\")
-	value = strings.ReplaceAll(value, "\"", "\\\"")
-	value = strings.ReplaceAll(value, "\n", "\\n")
-	value = strings.ReplaceAll(value, "\r", "\\r")
-	value = strings.ReplaceAll(value, "\t", "\\t")
-	return value
-}
-
-// boolPtr returns a pointer to a bool value
-func boolPtr(b bool) *bool {
-	return &b
-}
-
-// readEnvFile reads a .env file and returns environment variables as a map
-func readEnvFile(filename string) (map[string]string, error) {
-	// Validate filename to prevent path traversal
-	if err := validateFilePath(filename); err != nil {
-		return nil, fmt.Errorf("invalid file path: %w", err)
-	}
-
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
-	}
-
-	envVars := make(map[string]string)
-	lines := strings.Split(string(data), "\n")
-
-	for i, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Find the first = sign
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid line %d: %s", i+1, line)
-		}
-
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		// Remove quotes if present
-		if len(value) >= 2 {
-			if (value[0] == '"' && value[len(value)-1] == '"') ||
-				(value[0] == '\'' && value[len(value)-1] == '\'') {
-				value = value[1 : len(value)-1]
-				// Unescape common escape sequences
-				value = strings.ReplaceAll(value, `\\`, `\`)
-				value = strings.ReplaceAll(value, `\"`, `"`)
-				value = strings.ReplaceAll(value, `\n`, "\n")
-				value = strings.ReplaceAll(value, `\r`, "\r")
-				value = strings.ReplaceAll(value, `\t`, "\t")
-			}
-		}
-
-		envVars[key] = value
-	}
-
-	return envVars, nil
-}
-
-// pushSingleParameter pushes a single parameter to AWS SSM
-func pushSingleParameter(ctx context.Context, client *ssm.Client, key, value, ssmPath string) error {
-	input := &ssm.PutParameterInput{
-		Name:      &ssmPath,
-		Value:     &value,
-		Type:      "SecureString",
-		Overwrite: boolPtr(true),
-	}
-
-	_, err := client.PutParameter(ctx, input)
-	if err != nil {
-		return fmt.Errorf("failed to put parameter: %w", err)
-	}
-
-	return nil
-}
-
-// pushParameters pushes multiple parameters to AWS SSM based on mapping
-func pushParameters(ctx context.Context, client *ssm.Client, envVars map[string]string, paramMap ParameterMap) error {
-	for envKey, ssmPath := range paramMap {
-		value, exists := envVars[envKey]
-		if !exists {
-			// Skip parameters that don't exist in the .env file
-			continue
-		}
-
-		input := &ssm.PutParameterInput{
-			Name:      &ssmPath,
-			Value:     &value,
-			Type:      "SecureString",
-			Overwrite: boolPtr(true),
-		}
-
-		_, err := client.PutParameter(ctx, input)
-		if err != nil {
-			return fmt.Errorf("failed to put parameter %s: %w", envKey, err)
-		}
-	}
-
-	return nil
-}
-
-// Difference represents a parameter that differs between local and SSM
-type Difference struct {
-	Key       string
-	LocalVal  string
-	SSMVal    string
-	SSMPath   string
-	ExistsSSM bool
-}
-
-// syncParameters compares local .env with SSM values and updates the .env file
-func syncParameters(ctx context.Context, client *ssm.Client, localEnvVars map[string]string, paramMap ParameterMap, envFile string, force bool, quotes bool) error {
-	// Fetch current values from SSM
-	ssmEnvVars, err := fetchParameters(ctx, client, paramMap)
-	if err != nil {
-		return fmt.Errorf("failed to fetch SSM parameters: %w", err)
-	}
-
-	// Compare local and SSM values
-	var differences []Difference
-	for envKey, ssmPath := range paramMap {
-		localVal, localExists := localEnvVars[envKey]
-		ssmVal, ssmExists := ssmEnvVars[envKey]
-
-		// Check if there's a difference
-		if !localExists {
-			// Local doesn't have this key, but SSM does
-			if ssmExists {
-				differences = append(differences, Difference{
-					Key:       envKey,
-					LocalVal:  "",
-					SSMVal:    ssmVal,
-					SSMPath:   ssmPath,
-					ExistsSSM: true,
-				})
-			}
-		} else if !ssmExists {
-			// Local has the key but SSM doesn't - skip this
-			continue
-		} else if localVal != ssmVal {
-			// Both exist but values differ
-			differences = append(differences, Difference{
-				Key:       envKey,
-				LocalVal:  localVal,
-				SSMVal:    ssmVal,
-				SSMPath:   ssmPath,
-				ExistsSSM: true,
-			})
-		}
-	}
-
-	// If no differences found
-	if len(differences) == 0 {
-		fmt.Println("✓ All values are in sync. No updates needed.")
-		return nil
-	}
-
-	// Sort differences by key for consistent output
-	sort.Slice(differences, func(i, j int) bool {
-		return differences[i].Key < differences[j].Key
-	})
-
-	// Display differences
-	fmt.Printf("\nFound %d parameter(s) with differences:\n\n", len(differences))
-	for i, diff := range differences {
-		fmt.Printf("%d. %s\n", i+1, diff.Key)
-		if diff.LocalVal == "" {
-			fmt.Printf("   Local:  (not set)\n")
-		} else {
-			fmt.Printf("   Local:  %s\n", diff.LocalVal)
-		}
-		fmt.Printf("   SSM:    %s\n", diff.SSMVal)
-		fmt.Printf("   Path:   %s\n\n", diff.SSMPath)
-	}
-
-	// Determine which values to update
-	var toUpdate []Difference
-	if force {
-		// Force mode: update all differences
-		toUpdate = differences
-		fmt.Printf("Force mode enabled. Updating all %d parameter(s)...\n", len(toUpdate))
-	} else {
-		// Interactive mode: prompt for each difference
-		toUpdate, err = promptForUpdates(differences)
-		if err != nil {
-			return fmt.Errorf("error during prompting: %w", err)
-		}
-	}
-
-	if len(toUpdate) == 0 {
-		fmt.Println("No parameters selected for update.")
-		return nil
-	}
-
-	// Update local env vars with selected SSM values
-	for _, diff := range toUpdate {
-		localEnvVars[diff.Key] = diff.SSMVal
-	}
-
-	// Write updated values to .env file
-	err = writeEnvFile(envFile, localEnvVars, quotes)
-	if err != nil {
-		return fmt.Errorf("failed to write updated .env file: %w", err)
-	}
-
-	fmt.Printf("\n✓ Successfully updated %s with %d parameter(s) from SSM\n", envFile, len(toUpdate))
-	return nil
-}
-
-// promptForUpdates prompts the user to select which parameters to update
-func promptForUpdates(differences []Difference) ([]Difference, error) {
-	var toUpdate []Difference
-
-	for i, diff := range differences {
-		for {
-			fmt.Printf("Update %s (%d/%d)? [y]es/[n]o/[a]ll/[c]ancel: ", diff.Key, i+1, len(differences))
-
-			var response string
-			_, err := fmt.Scanln(&response)
-			if err != nil {
-				// Handle empty input
-				response = ""
-			}
-
-			response = strings.ToLower(strings.TrimSpace(response))
-
-			switch response {
-			case "y", "yes":
-				toUpdate = append(toUpdate, diff)
-				goto nextDiff
-			case "n", "no":
-				goto nextDiff
-			case "a", "all":
-				// Add current and all remaining differences
-				toUpdate = append(toUpdate, differences[i:]...)
-				return toUpdate, nil
-			case "c", "cancel":
-				return toUpdate, nil
-			default:
-				fmt.Println("Invalid input. Please enter y(es), n(o), a(ll), or c(ancel).")
-			}
-		}
-	nextDiff:
-	}
-
-	return toUpdate, nil
-}
-
-func main() {
-	// Print ASCII artwork
-	fmt.Print(asciiArt)
-
-	// Define command-line flags
-	mapFile := flag.String("map", "", "Path to JSON file mapping env vars to SSM parameter paths or Azure secret names")
-	envFile := flag.String("env", ".env", "Path to .env file (for pull: output file, for push: input file)")
-	profile := flag.String("profile", "", "AWS profile to use")
-	region := flag.String("region", "", "AWS region to use")
-	showVersion := flag.Bool("version", false, "Show version information")
-	push := flag.Bool("push", false, "Push mode: upload local .env to SSM")
-	sync := flag.Bool("sync", false, "Sync mode: compare .env with SSM and update differences")
-	force := flag.Bool("force", false, "Force mode: update all differences without prompting (only with --sync)")
-	key := flag.String("key", "", "Single environment variable name to push (only with --push)")
-	value := flag.String("value", "", "Value of the single environment variable to push (only with --push)")
-	ssmPath := flag.String("ssm-path", "", "SSM path for the single environment variable (only with --push and AWS)")
-	secretName := flag.String("secret-name", "", "Azure Key Vault secret name for the single environment variable (only with --push and --azure)")
-	quotes := flag.Bool("quotes", false, "Always quote values in the .env file output")
-	azure := flag.Bool("azure", false, "Use Azure Key Vault instead of AWS SSM")
-	vaultName := flag.String("vault-name", "", "Azure Key Vault name (required with --azure)")
-
-	flag.Parse()
-
-	if *showVersion {
-		fmt.Printf("EnvChanter %s\n", version)
-		os.Exit(0)
-	}
-
-	// Validate flags based on mode
-	if *push && *sync {
-		fmt.Println("Error: Cannot use --push and --sync together")
-		fmt.Println("\nUsage:")
-		flag.PrintDefaults()
-		os.Exit(1)
-	}
-
-	// Azure-specific validation
-	if *azure {
-		if *vaultName == "" {
-			fmt.Println("Error: --vault-name is required when using --azure")
-			fmt.Println("\nUsage:")
-			flag.PrintDefaults()
-			os.Exit(1)
-		}
-	}
-
-	if *push {
-		// Push mode validation
-		if *key != "" || *value != "" || *ssmPath != "" || *secretName != "" {
-			// Single parameter push mode
-			if *azure {
-				// Azure single parameter push
-				if *key == "" || *value == "" || *secretName == "" {
-					fmt.Println("Error: For Azure single parameter push, all of --key, --value, and --secret-name are required")
-					fmt.Println("\nUsage:")
-					flag.PrintDefaults()
-					os.Exit(1)
-				}
-			} else {
-				// AWS single parameter push
-				if *key == "" || *value == "" || *ssmPath == "" {
-					fmt.Println("Error: For AWS single parameter push, all of --key, --value, and --ssm-path are required")
-					fmt.Println("\nUsage:")
-					flag.PrintDefaults()
-					os.Exit(1)
-				}
-			}
-		} else {
-			// File-based push mode
-			if *mapFile == "" || *envFile == "" {
-				fmt.Println("Error: For file-based push, both --map and --env are required")
-				fmt.Println("\nUsage:")
-				flag.PrintDefaults()
-				os.Exit(1)
-			}
-		}
-	} else if *sync {
-		// Sync mode validation
-		if *mapFile == "" || *envFile == "" {
-			fmt.Println("Error: For sync mode, both --map and --env are required")
-			fmt.Println("\nUsage:")
-			flag.PrintDefaults()
-			os.Exit(1)
-		}
-	} else {
-		// Pull mode validation (existing behavior)
-		if *mapFile == "" {
-			fmt.Println("Error: --map flag is required")
-			fmt.Println("\nUsage:")
-			flag.PrintDefaults()
-			os.Exit(1)
-		}
-	}
-
-	ctx := context.Background()
-
-	// Handle Azure mode
-	if *azure {
-		// Create Azure client
-		azureClient, err := createAzureClient(ctx, *vaultName)
-		if err != nil {
-			fmt.Printf("Error creating Azure Key Vault client: %v\n", err)
-			os.Exit(1)
-		}
-
-		if *push {
-			// Azure push mode
-			if *key != "" {
-				// Validate key and secret name before pushing
-				if err := validateEnvVarName(*key); err != nil {
-					fmt.Printf("Error: invalid environment variable name: %v\n", err)
-					os.Exit(1)
-				}
-				if err := validateAzureSecretName(*secretName); err != nil {
-					fmt.Printf("Error: invalid Azure secret name: %v\n", err)
-					os.Exit(1)
-				}
-
-				// Single parameter push to Azure
-				err = pushSingleParameterToAzure(ctx, azureClient, *key, *value, *secretName)
-				if err != nil {
-					fmt.Printf("Error pushing secret: %v\n", err)
-					os.Exit(1)
-				}
-				fmt.Printf("Successfully pushed %s to Azure Key Vault secret %s\n", *key, *secretName)
-			} else {
-				// File-based push to Azure
-				paramMap, err := loadParameterMapRaw(*mapFile)
-				if err != nil {
-					fmt.Printf("Error loading parameter map: %v\n", err)
-					os.Exit(1)
-				}
-
-				// Validate parameter map for Azure
-				if err := validateAzureParameterMap(paramMap); err != nil {
-					fmt.Printf("Error: invalid parameter map: %v\n", err)
-					os.Exit(1)
-				}
-
-				envVars, err := readEnvFile(*envFile)
-				if err != nil {
-					fmt.Printf("Error reading .env file: %v\n", err)
-					os.Exit(1)
-				}
-
-				err = pushParametersToAzure(ctx, azureClient, envVars, paramMap)
-				if err != nil {
-					fmt.Printf("Error pushing secrets: %v\n", err)
-					os.Exit(1)
-				}
-				fmt.Printf("Successfully pushed %d secrets to Azure Key Vault\n", len(envVars))
-			}
-		} else if *sync {
-			// Azure sync mode
-			paramMap, err := loadParameterMapRaw(*mapFile)
-			if err != nil {
-				fmt.Printf("Error loading parameter map: %v\n", err)
-				os.Exit(1)
-			}
-
-			// Validate parameter map for Azure
-			if err := validateAzureParameterMap(paramMap); err != nil {
-				fmt.Printf("Error: invalid parameter map: %v\n", err)
-				os.Exit(1)
-			}
-
-			localEnvVars, err := readEnvFile(*envFile)
-			if err != nil {
-				fmt.Printf("Error reading .env file: %v\n", err)
-				os.Exit(1)
-			}
-
-			err = syncParametersWithAzure(ctx, azureClient, localEnvVars, paramMap, *envFile, *force, *quotes)
-			if err != nil {
-				fmt.Printf("Error syncing secrets: %v\n", err)
-				os.Exit(1)
-			}
-		} else {
-			// Azure pull mode
-			paramMap, err := loadParameterMapRaw(*mapFile)
-			if err != nil {
-				fmt.Printf("Error loading parameter map: %v\n", err)
-				os.Exit(1)
-			}
-
-			// Validate parameter map for Azure
-			if err := validateAzureParameterMap(paramMap); err != nil {
-				fmt.Printf("Error: invalid parameter map: %v\n", err)
-				os.Exit(1)
-			}
-
-			// Fetch secrets from Azure
-			envVars, err := fetchParametersFromAzure(ctx, azureClient, paramMap)
-			if err != nil {
-				fmt.Printf("Error fetching secrets: %v\n", err)
-				os.Exit(1)
-			}
-
-			// Write .env file
-			err = writeEnvFile(*envFile, envVars, *quotes)
-			if err != nil {
-				fmt.Printf("Error writing .env file: %v\n", err)
-				os.Exit(1)
-			}
-
-			fmt.Printf("Successfully generated %s with %d secrets from Azure Key Vault\n", *envFile, len(envVars))
-		}
-		return
-	}
-
-	// Create AWS config
-	cfg, err := loadAWSConfig(ctx, *profile, *region)
-	if err != nil {
-		fmt.Printf("Error loading AWS config: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Create SSM client
-	ssmClient := ssm.NewFromConfig(cfg)
-
-	if *push {
-		// Push mode
-		if *key != "" {
-			// Validate key and SSM path before pushing
-			if err := validateEnvVarName(*key); err != nil {
-				fmt.Printf("Error: invalid environment variable name: %v\n", err)
-				os.Exit(1)
-			}
-			if err := validateSSMPath(*ssmPath); err != nil {
-				fmt.Printf("Error: invalid SSM path: %v\n", err)
-				os.Exit(1)
-			}
-
-			// Single parameter push
-			err = pushSingleParameter(ctx, ssmClient, *key, *value, *ssmPath)
-			if err != nil {
-				fmt.Printf("Error pushing parameter: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Printf("Successfully pushed %s to %s\n", *key, *ssmPath)
-		} else {
-			// File-based push
-			paramMap, err := loadParameterMap(*mapFile)
-			if err != nil {
-				fmt.Printf("Error loading parameter map: %v\n", err)
-				os.Exit(1)
-			}
-
-			envVars, err := readEnvFile(*envFile)
-			if err != nil {
-				fmt.Printf("Error reading .env file: %v\n", err)
-				os.Exit(1)
-			}
-
-			err = pushParameters(ctx, ssmClient, envVars, paramMap)
-			if err != nil {
-				fmt.Printf("Error pushing parameters: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Printf("Successfully pushed %d parameters to SSM\n", len(envVars))
-		}
-	} else if *sync {
-		// Sync mode
-		paramMap, err := loadParameterMap(*mapFile)
-		if err != nil {
-			fmt.Printf("Error loading parameter map: %v\n", err)
-			os.Exit(1)
-		}
-
-		localEnvVars, err := readEnvFile(*envFile)
-		if err != nil {
-			fmt.Printf("Error reading .env file: %v\n", err)
-			os.Exit(1)
-		}
-
-		err = syncParameters(ctx, ssmClient, localEnvVars, paramMap, *envFile, *force, *quotes)
-		if err != nil {
-			fmt.Printf("Error syncing parameters: %v\n", err)
-			os.Exit(1)
-		}
-	} else {
-		// Pull mode (existing behavior)
-		paramMap, err := loadParameterMap(*mapFile)
-		if err != nil {
-			fmt.Printf("Error loading parameter map: %v\n", err)
-			os.Exit(1)
-		}
-
-		envVars, err := fetchParameters(ctx, ssmClient, paramMap)
-		if err != nil {
-			fmt.Printf("Error fetching parameters: %v\n", err)
-			os.Exit(1)
-		}
-
-		err = writeEnvFile(*envFile, envVars, *quotes)
-		if err != nil {
-			fmt.Printf("Error writing .env file: %v\n", err)
-			os.Exit(1)
-		}
-
-		fmt.Printf("Successfully generated %s with %d parameters\n", *envFile, len(envVars))
-	}
-}
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var version = "dev"
+
+const asciiArt = `
+███████╗███╗   ██╗██╗   ██╗ ██████╗██╗  ██╗ █████╗ ███╗   ██╗████████╗███████╗██████╗ 
+██╔════╝████╗  ██║██║   ██║██╔════╝██║  ██║██╔══██╗████╗  ██║╚══██╔══╝██╔════╝██╔══██╗
+█████╗  ██╔██╗ ██║██║   ██║██║     ███████║███████║██╔██╗ ██║   ██║   █████╗  ██████╔╝
+██╔══╝  ██║╚██╗██║╚██╗ ██╔╝██║     ██╔══██║██╔══██║██║╚██╗██║   ██║   ██╔══╝  ██╔══██╗
+███████╗██║ ╚████║ ╚████╔╝ ╚██████╗██║  ██║██║  ██║██║ ╚████║   ██║   ███████╗██║  ██║
+╚══════╝╚═╝  ╚═══╝  ╚═══╝   ╚═════╝╚═╝  ╚═╝╚═╝  ╚═╝╚═╝  ╚═══╝   ╚═╝   ╚══════╝╚═╝  ╚═╝
+`
+
+type ParameterMap map[string]string
+
+// validateFilePath validates a file path to prevent path traversal attacks
+func validateFilePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("empty file path")
+	}
+
+	// Check for path traversal attempts
+	cleanPath := strings.TrimSpace(path)
+	if strings.Contains(cleanPath, "..") {
+		return fmt.Errorf("path traversal detected")
+	}
+
+	// Check for null bytes
+	if strings.Contains(cleanPath, "\x00") {
+		return fmt.Errorf("null byte in file path")
+	}
+
+	return nil
+}
+
+// validateParameterMap validates the contents of a parameter map
+func validateParameterMap(paramMap ParameterMap) error {
+	if len(paramMap) == 0 {
+		return fmt.Errorf("parameter map is empty")
+	}
+
+	for envKey, ssmPath := range paramMap {
+		// The prefix entry names a hierarchy rather than a single parameter
+		if envKey == prefixMapKey {
+			if err := validateSSMPath(ssmPath); err != nil {
+				return fmt.Errorf("invalid SSM path prefix %q: %w", ssmPath, err)
+			}
+			continue
+		}
+
+		// Validate environment variable name
+		if err := validateEnvVarName(envKey); err != nil {
+			return fmt.Errorf("invalid environment variable name %q: %w", envKey, err)
+		}
+
+		// Validate SSM path
+		if err := validateSSMPath(ssmPath); err != nil {
+			return fmt.Errorf("invalid SSM path %q for key %q: %w", ssmPath, envKey, err)
+		}
+	}
+
+	return nil
+}
+
+// validateEnvVarName validates an environment variable name
+func validateEnvVarName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty environment variable name")
+	}
+
+	// Environment variable names should only contain alphanumeric characters and underscores
+	// and should not start with a digit
+	for i, char := range name {
+		if i == 0 && char >= '0' && char <= '9' {
+			return fmt.Errorf("environment variable name cannot start with a digit")
+		}
+		if !((char >= 'A' && char <= 'Z') || (char >= 'a' && char <= 'z') ||
+			(char >= '0' && char <= '9') || char == '_') {
+			return fmt.Errorf("environment variable name contains invalid character: %c", char)
+		}
+	}
+
+	return nil
+}
+
+// validateSSMPath validates an AWS SSM parameter path
+func validateSSMPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("empty SSM path")
+	}
+
+	// SSM parameter names must start with /
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("SSM path must start with /")
+	}
+
+	// Check for invalid characters (AWS SSM allows alphanumeric, -, _, ., and /)
+	for _, char := range path {
+		if !((char >= 'A' && char <= 'Z') || (char >= 'a' && char <= 'z') ||
+			(char >= '0' && char <= '9') || char == '-' || char == '_' ||
+			char == '.' || char == '/') {
+			return fmt.Errorf("SSM path contains invalid character: %c", char)
+		}
+	}
+
+	// Check for path traversal attempts
+	if strings.Contains(path, "..") {
+		return fmt.Errorf("path traversal detected in SSM path")
+	}
+
+	// Check length (AWS SSM has a max path length of 2048 characters)
+	if len(path) > 2048 {
+		return fmt.Errorf("SSM path exceeds maximum length of 2048 characters")
+	}
+
+	return nil
+}
+
+// validateAzureSecretName validates an Azure Key Vault secret name
+func validateAzureSecretName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty secret name")
+	}
+
+	// Azure Key Vault secret names must be 1-127 characters long and contain only alphanumeric characters and hyphens
+	if len(name) > 127 {
+		return fmt.Errorf("secret name exceeds maximum length of 127 characters")
+	}
+
+	for _, char := range name {
+		if !((char >= 'A' && char <= 'Z') || (char >= 'a' && char <= 'z') ||
+			(char >= '0' && char <= '9') || char == '-') {
+			return fmt.Errorf("secret name contains invalid character: %c (only alphanumeric and hyphens allowed)", char)
+		}
+	}
+
+	return nil
+}
+
+// validateAzureParameterMap validates the contents of a parameter map for Azure Key Vault
+func validateAzureParameterMap(paramMap ParameterMap) error {
+	if len(paramMap) == 0 {
+		return fmt.Errorf("parameter map is empty")
+	}
+
+	for envKey, secretName := range paramMap {
+		// The prefix entry names a secret name prefix rather than a single secret
+		if envKey == prefixMapKey {
+			if err := validateAzureSecretName(secretName); err != nil {
+				return fmt.Errorf("invalid Azure secret name prefix %q: %w", secretName, err)
+			}
+			continue
+		}
+
+		// Validate environment variable name
+		if err := validateEnvVarName(envKey); err != nil {
+			return fmt.Errorf("invalid environment variable name %q: %w", envKey, err)
+		}
+
+		// Validate Azure secret name
+		if err := validateAzureSecretName(secretName); err != nil {
+			return fmt.Errorf("invalid Azure secret name %q for key %q: %w", secretName, envKey, err)
+		}
+	}
+
+	return nil
+}
+
+// validateSecretsManagerPath validates a Secrets Manager path: a secret name or ARN, optionally
+// followed by ?stage=VERSION_STAGE and #json-key
+func validateSecretsManagerPath(path string) error {
+	ref, err := parseSecretRef(path)
+	if err != nil {
+		return err
+	}
+
+	if err := validateSecretID(ref.ID); err != nil {
+		return err
+	}
+
+	// Version stages are labels such as AWSCURRENT, AWSPREVIOUS or a custom label
+	if len(ref.Stage) > 256 {
+		return fmt.Errorf("version stage exceeds maximum length of 256 characters")
+	}
+	for _, char := range ref.Stage {
+		if !((char >= 'A' && char <= 'Z') || (char >= 'a' && char <= 'z') ||
+			(char >= '0' && char <= '9') || char == '-' || char == '_' || char == '.') {
+			return fmt.Errorf("version stage contains invalid character: %c", char)
+		}
+	}
+
+	return nil
+}
+
+// validateSecretID validates a Secrets Manager secret name or ARN
+func validateSecretID(id string) error {
+	if id == "" {
+		return fmt.Errorf("empty secret name")
+	}
+
+	// ARNs may also contain the colons that separate their fields
+	isARN := strings.HasPrefix(id, "arn:")
+	if isARN && !strings.Contains(id, ":secretsmanager:") {
+		return fmt.Errorf("ARN is not a Secrets Manager ARN")
+	}
+	if !isARN && len(id) > 512 {
+		return fmt.Errorf("secret name exceeds maximum length of 512 characters")
+	}
+	if len(id) > 2048 {
+		return fmt.Errorf("secret ARN exceeds maximum length of 2048 characters")
+	}
+
+	// Secrets Manager allows alphanumeric characters and /_+=.@-
+	for _, char := range id {
+		if !((char >= 'A' && char <= 'Z') || (char >= 'a' && char <= 'z') ||
+			(char >= '0' && char <= '9') || strings.ContainsRune("/_+=.@-", char) || (isARN && char == ':')) {
+			return fmt.Errorf("secret name contains invalid character: %c (only alphanumeric and /_+=.@- allowed)", char)
+		}
+	}
+
+	return nil
+}
+
+// validateSecretsManagerMap validates the contents of a parameter map for Secrets Manager
+func validateSecretsManagerMap(paramMap ParameterMap) error {
+	if len(paramMap) == 0 {
+		return fmt.Errorf("parameter map is empty")
+	}
+
+	for envKey, path := range paramMap {
+		// The prefix entry names a secret name prefix rather than a single secret
+		if envKey == prefixMapKey {
+			if err := validateSecretID(path); err != nil {
+				return fmt.Errorf("invalid Secrets Manager name prefix %q: %w", path, err)
+			}
+			continue
+		}
+
+		// Validate environment variable name
+		if err := validateEnvVarName(envKey); err != nil {
+			return fmt.Errorf("invalid environment variable name %q: %w", envKey, err)
+		}
+
+		// Validate Secrets Manager path
+		if err := validateSecretsManagerPath(path); err != nil {
+			return fmt.Errorf("invalid Secrets Manager secret %q for key %q: %w", path, envKey, err)
+		}
+	}
+
+	return nil
+}
+
+// validateVaultSecretPath validates the path of a secret, folder or mount in Vault
+func validateVaultSecretPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("empty Vault path")
+	}
+
+	if strings.HasPrefix(path, "/") {
+		return fmt.Errorf("Vault path must not start with /")
+	}
+
+	// Keep to characters that need no escaping in the Vault HTTP API
+	for _, char := range path {
+		if !((char >= 'A' && char <= 'Z') || (char >= 'a' && char <= 'z') ||
+			(char >= '0' && char <= '9') || char == '-' || char == '_' ||
+			char == '.' || char == '/' || char == '@' || char == '+' || char == '=') {
+			return fmt.Errorf("Vault path contains invalid character: %c", char)
+		}
+	}
+
+	// Check for path traversal attempts
+	if strings.Contains(path, "..") || strings.Contains(path, "//") {
+		return fmt.Errorf("path traversal detected in Vault path")
+	}
+
+	return nil
+}
+
+// validateVaultPath validates a Vault path: a secret path, optionally followed by ?version=N
+// (KV v2 only) and #field
+func validateVaultPath(path string, kvVersion int) error {
+	ref, err := parseVaultRef(path)
+	if err != nil {
+		return err
+	}
+
+	if err := validateVaultSecretPath(ref.Path); err != nil {
+		return err
+	}
+	if strings.HasSuffix(ref.Path, "/") {
+		return fmt.Errorf("Vault path names a folder, not a secret")
+	}
+
+	if ref.Version > 0 && kvVersion != 2 {
+		return fmt.Errorf("versions can only be read from a KV version 2 secrets engine")
+	}
+
+	return nil
+}
+
+// validateVaultParameterMap validates the contents of a parameter map for Vault
+func validateVaultParameterMap(paramMap ParameterMap, kvVersion int) error {
+	if len(paramMap) == 0 {
+		return fmt.Errorf("parameter map is empty")
+	}
+
+	for envKey, path := range paramMap {
+		// The prefix entry names a folder or path prefix rather than a single secret
+		if envKey == prefixMapKey {
+			if err := validateVaultSecretPath(path); err != nil {
+				return fmt.Errorf("invalid Vault path prefix %q: %w", path, err)
+			}
+			continue
+		}
+
+		// Validate environment variable name
+		if err := validateEnvVarName(envKey); err != nil {
+			return fmt.Errorf("invalid environment variable name %q: %w", envKey, err)
+		}
+
+		// Validate Vault path
+		if err := validateVaultPath(path, kvVersion); err != nil {
+			return fmt.Errorf("invalid Vault path %q for key %q: %w", path, envKey, err)
+		}
+	}
+
+	return nil
+}
+
+// loadParameterMapRaw reads the JSON, YAML or TOML mapping file and the maps it extends without
+// validation, returning the paths and the specs of any entries written in object form
+func loadParameterMapRaw(filename string) (ParameterMap, ParameterSpecs, error) {
+	entries, err := loadMapEntries(filename, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paramMap, specs, err := parseMapEntries(entries)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse mapping: %w", err)
+	}
+
+	return paramMap, specs, nil
+}
+
+// loadParameterMap reads the mapping file, fills in ${name} placeholders from vars and
+// validates it for the given provider
+func loadParameterMap(filename string, provider SecretProvider, vars map[string]string) (ParameterMap, ParameterSpecs, error) {
+	paramMap, specs, err := loadParameterMapRaw(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paramMap, err = interpolateParameterMap(paramMap, vars)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid parameter map: %w", err)
+	}
+	for envKey, spec := range specs {
+		spec.Path = paramMap[envKey]
+		specs[envKey] = spec
+	}
+
+	// Validate parameter map contents for the provider
+	if err := provider.ValidateMap(paramMap); err != nil {
+		return nil, nil, fmt.Errorf("invalid parameter map: %w", err)
+	}
+
+	return paramMap, specs, nil
+}
+
+// fetchResult holds the outcome of fetching a single parameter
+type fetchResult struct {
+	value string
+	found bool
+}
+
+// fetchParameters retrieves parameter values from the secret provider, warning about every
+// mapped parameter that does not exist
+func fetchParameters(ctx context.Context, provider SecretProvider, paramMap ParameterMap, concurrency int) (map[string]string, error) {
+	envVars, err := fetchExistingParameters(ctx, provider, paramMap, concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, envKey := range sortedKeys(paramMap) {
+		if _, found := envVars[envKey]; !found {
+			// If the parameter does not exist, log a warning and continue
+			fmt.Fprintf(statusOut, "Warning: parameter not found for %s, skipping.\n", envKey)
+		}
+	}
+
+	return envVars, nil
+}
+
+// fetchExistingParameters retrieves the values of the parameters that exist, running up to
+// concurrency requests in parallel. Providers that support batching receive up to
+// BatchSize paths per request. The first fatal error cancels the remaining requests.
+func fetchExistingParameters(ctx context.Context, provider SecretProvider, paramMap ParameterMap, concurrency int) (map[string]string, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Fetch in key order so batches are deterministic
+	keys := sortedKeys(paramMap)
+	results := make([]fetchResult, len(keys))
+
+	// Split the keys into batches of indices
+	batchSize := 1
+	if batcher, ok := provider.(batchGetter); ok {
+		batchSize = max(batcher.BatchSize(), 1)
+	}
+	var batches [][]int
+	for start := 0; start < len(keys); start += batchSize {
+		batch := make([]int, 0, batchSize)
+		for i := start; i < min(start+batchSize, len(keys)); i++ {
+			batch = append(batch, i)
+		}
+		batches = append(batches, batch)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	jobs := make(chan []int)
+	for w := 0; w < min(concurrency, len(batches)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				paths := make([]string, 0, len(batch))
+				batchKeys := make([]string, 0, len(batch))
+				for _, i := range batch {
+					paths = append(paths, paramMap[keys[i]])
+					batchKeys = append(batchKeys, keys[i])
+				}
+
+				values, err := fetchBatch(ctx, provider, paths)
+				if err != nil {
+					// Fail without exposing the paths. A batch error may come from any one of its
+					// keys, so only a single key is named as the cause.
+					errOnce.Do(func() {
+						if len(batchKeys) == 1 {
+							firstErr = fmt.Errorf("failed to get parameter for %s: %w", batchKeys[0], err)
+						} else {
+							firstErr = fmt.Errorf("failed to get a batch of parameters (%s): %w", strings.Join(batchKeys, ", "), err)
+						}
+						cancel()
+					})
+					continue
+				}
+
+				for _, i := range batch {
+					if value, ok := values[paramMap[keys[i]]]; ok {
+						results[i] = fetchResult{value: value, found: true}
+					}
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, batch := range batches {
+		select {
+		case jobs <- batch:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	envVars := make(map[string]string)
+	for i, envKey := range keys {
+		if results[i].found {
+			envVars[envKey] = results[i].value
+		}
+	}
+
+	return envVars, nil
+}
+
+// fetchBatch fetches a batch of paths, using a single request when the provider supports it.
+// Paths that do not exist are omitted from the result.
+func fetchBatch(ctx context.Context, provider SecretProvider, paths []string) (map[string]string, error) {
+	if batcher, ok := provider.(batchGetter); ok {
+		return batcher.GetBatch(ctx, paths)
+	}
+
+	values := make(map[string]string, len(paths))
+	for _, path := range paths {
+		value, err := provider.Get(ctx, path)
+		if errors.Is(err, errSecretNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[path] = value
+	}
+
+	return values, nil
+}
+
+// writeEnvFile writes environment variables to a .env file
+func writeEnvFile(filename string, envVars map[string]string, alwaysQuote bool) error {
+	return writeOutputFile(filename, formatDotenv, envVars, outputOptions{Quotes: alwaysQuote})
+}
+
+// needsQuoting checks if a value needs to be quoted, so it reads back unchanged
+func needsQuoting(value string) bool {
+	return strings.ContainsAny(value, " \t\n\r\"'\\#$")
+}
+
+// escapeValue escapes special characters in a value
+func escapeValue(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "\"", "\\\"")
+	value = strings.ReplaceAll(value, "$", "\\$")
+	value = strings.ReplaceAll(value, "\n", "\\n")
+	value = strings.ReplaceAll(value, "\r", "\\r")
+	value = strings.ReplaceAll(value, "\t", "\\t")
+	return value
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// boolPtr returns a pointer to a bool value
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// readEnvFile reads a .env file and returns environment variables as a map. When a key is
+// assigned more than once, the last assignment wins.
+func readEnvFile(filename string) (map[string]string, error) {
+	// Validate filename to prevent path traversal
+	if err := validateFilePath(filename); err != nil {
+		return nil, fmt.Errorf("invalid file path: %w", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	entries, err := parseDotenv(data, os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	envVars := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		envVars[entry.Key] = entry.Value
+	}
+
+	return envVars, nil
+}
+
+// pushParameters pushes the mapped .env values to the secret provider, skipping values that
+// are already up to date, and returns the action taken for each key
+func pushParameters(ctx context.Context, provider SecretProvider, envVars map[string]string, paramMap ParameterMap, concurrency int) ([]pushChange, error) {
+	// Read the current values first so unchanged parameters don't get a new version
+	changes, err := planPush(ctx, provider, envVars, paramMap, concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	var toPut []Difference
+	for _, change := range changes {
+		if change.Action != pushUnchanged {
+			toPut = append(toPut, change.Difference)
+		}
+	}
+
+	if err := putDifferences(ctx, provider, toPut); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// putDifferences writes the local values of diffs to the provider. Providers that keep several
+// values in one secret get them in a single batch, so each secret is written once.
+func putDifferences(ctx context.Context, provider SecretProvider, diffs []Difference) error {
+	if putter, ok := provider.(batchPutter); ok && len(diffs) > 0 {
+		values := make(map[string]string, len(diffs))
+		for _, diff := range diffs {
+			values[diff.RemotePath] = diff.LocalVal
+		}
+		if err := putter.PutBatch(ctx, values); err != nil {
+			return fmt.Errorf("failed to put parameters: %w", err)
+		}
+		return nil
+	}
+
+	for _, diff := range diffs {
+		if err := provider.Put(ctx, diff.RemotePath, diff.LocalVal); err != nil {
+			return fmt.Errorf("failed to put parameter %s: %w", diff.Key, err)
+		}
+	}
+	return nil
+}
+
+// Difference represents a parameter that differs between local and the remote provider
+type Difference struct {
+	Key          string
+	LocalVal     string
+	RemoteVal    string
+	RemotePath   string
+	ExistsRemote bool
+}
+
+// findDifferences compares local and remote values for every mapped key, sorted by key
+func findDifferences(localEnvVars, remoteEnvVars map[string]string, paramMap ParameterMap) []Difference {
+	var differences []Difference
+	for envKey, path := range paramMap {
+		localVal, localExists := localEnvVars[envKey]
+		remoteVal, remoteExists := remoteEnvVars[envKey]
+
+		// Check if there's a difference
+		if !localExists {
+			// Local doesn't have this key, but the remote does
+			if remoteExists {
+				differences = append(differences, Difference{
+					Key:          envKey,
+					LocalVal:     "",
+					RemoteVal:    remoteVal,
+					RemotePath:   path,
+					ExistsRemote: true,
+				})
+			}
+		} else if !remoteExists {
+			// Local has the key but the remote doesn't - skip this
+			continue
+		} else if localVal != remoteVal {
+			// Both exist but values differ
+			differences = append(differences, Difference{
+				Key:          envKey,
+				LocalVal:     localVal,
+				RemoteVal:    remoteVal,
+				RemotePath:   path,
+				ExistsRemote: true,
+			})
+		}
+	}
+
+	// Sort differences by key for consistent output
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Key < differences[j].Key
+	})
+
+	return differences
+}
+
+// findPushDifferences compares local and remote values for every mapped key in the
+// local-to-remote direction: keys set locally that are missing or different remotely
+func findPushDifferences(localEnvVars, remoteEnvVars map[string]string, paramMap ParameterMap) []Difference {
+	var differences []Difference
+	for envKey, path := range paramMap {
+		localVal, localExists := localEnvVars[envKey]
+		if !localExists {
+			// Nothing to push for keys that are not in the .env file
+			continue
+		}
+
+		remoteVal, remoteExists := remoteEnvVars[envKey]
+		if remoteExists && localVal == remoteVal {
+			continue
+		}
+
+		differences = append(differences, Difference{
+			Key:          envKey,
+			LocalVal:     localVal,
+			RemoteVal:    remoteVal,
+			RemotePath:   path,
+			ExistsRemote: remoteExists,
+		})
+	}
+
+	// Sort differences by key for consistent output
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Key < differences[j].Key
+	})
+
+	return differences
+}
+
+// Sync directions
+const (
+	syncRemoteToLocal = "remote-to-local"
+	syncLocalToRemote = "local-to-remote"
+)
+
+// syncOptions controls how syncParameters fetches, displays and applies differences
+type syncOptions struct {
+	Force       bool
+	Quotes      bool
+	Concurrency int
+	ShowValues  bool
+	Direction   string
+	Backup      backupMode
+}
+
+// syncParameters compares local .env with the provider's values and, depending on the
+// direction, updates the .env file or pushes the selected local values to the provider.
+// When updating the .env file, keys missing remotely get their default from specs, as in a pull.
+func syncParameters(ctx context.Context, provider SecretProvider, localEnvVars map[string]string, paramMap ParameterMap, specs ParameterSpecs, envFile string, opts syncOptions) error {
+	// Fetch current values from the provider. Pushing creates missing parameters, so they
+	// are only worth a warning when updating the .env file.
+	toRemote := opts.Direction == syncLocalToRemote
+	fetch := fetchParameters
+	if toRemote {
+		fetch = fetchExistingParameters
+	}
+	remoteEnvVars, err := fetch(ctx, provider, paramMap, opts.Concurrency)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s parameters: %w", provider.Name(), err)
+	}
+
+	// Compare local and remote values
+	var differences []Difference
+	if toRemote {
+		differences = findPushDifferences(localEnvVars, remoteEnvVars, paramMap)
+	} else {
+		applyDefaults(remoteEnvVars, specs)
+		differences = findDifferences(localEnvVars, remoteEnvVars, paramMap)
+	}
+
+	// If no differences found
+	if len(differences) == 0 {
+		fmt.Println("✓ All values are in sync. No updates needed.")
+		return nil
+	}
+
+	// Display differences
+	fmt.Printf("\nFound %d parameter(s) with differences:\n\n", len(differences))
+	for i, diff := range differences {
+		fmt.Printf("%d. %s\n", i+1, diff.Key)
+		printDifferenceValues(diff, provider.Name(), opts.ShowValues)
+		fmt.Printf("   Path:   %s\n\n", diff.RemotePath)
+	}
+
+	// Determine which values to update
+	var toUpdate []Difference
+	if opts.Force {
+		// Force mode: update all differences
+		toUpdate = differences
+		fmt.Printf("Force mode enabled. Updating all %d parameter(s)...\n", len(toUpdate))
+	} else {
+		// Interactive mode: prompt for each difference
+		toUpdate, err = promptForUpdates(differences, provider.Name(), !opts.ShowValues)
+		if err != nil {
+			return fmt.Errorf("error during prompting: %w", err)
+		}
+	}
+
+	if len(toUpdate) == 0 {
+		fmt.Println("No parameters selected for update.")
+		return nil
+	}
+
+	if toRemote {
+		// Push only the selected local values
+		if err := putDifferences(ctx, provider, toUpdate); err != nil {
+			return err
+		}
+
+		fmt.Printf("\n✓ Successfully pushed %d parameter(s) from %s to %s\n", len(toUpdate), envFile, provider.Name())
+		return nil
+	}
+
+	// Update the selected values in the .env file, leaving the rest of it as it is
+	updates := make(map[string]string, len(toUpdate))
+	for _, diff := range toUpdate {
+		updates[diff.Key] = diff.RemoteVal
+	}
+
+	err = updateEnvFile(envFile, updates, outputOptions{Quotes: opts.Quotes, Backup: opts.Backup})
+	if err != nil {
+		return fmt.Errorf("failed to write updated .env file: %w", err)
+	}
+
+	fmt.Printf("\n✓ Successfully updated %s with %d parameter(s) from %s\n", envFile, len(toUpdate), provider.Name())
+	return nil
+}
+
+// statusOut receives the banner and status messages. It is switched to stderr when stdout
+// carries machine-readable output.
+var statusOut io.Writer = os.Stdout
+
+// promptInput is where interactive answers are read from
+var promptInput io.Reader = os.Stdin
+
+// promptForUpdates prompts the user to select which parameters to update. When values are
+// masked, the user can reveal a single difference in plaintext before answering.
+func promptForUpdates(differences []Difference, remoteName string, masked bool) ([]Difference, error) {
+	var toUpdate []Difference
+
+	options := "[y]es/[n]o/[a]ll/[c]ancel"
+	if masked {
+		options = "[y]es/[n]o/[a]ll/[s]how/[c]ancel"
+	}
+
+	for i, diff := range differences {
+		for {
+			fmt.Printf("Update %s (%d/%d)? %s: ", diff.Key, i+1, len(differences), options)
+
+			var response string
+			_, err := fmt.Fscanln(promptInput, &response)
+			if err != nil {
+				// Handle empty input
+				response = ""
+			}
+
+			response = strings.ToLower(strings.TrimSpace(response))
+
+			switch response {
+			case "y", "yes":
+				toUpdate = append(toUpdate, diff)
+				goto nextDiff
+			case "n", "no":
+				goto nextDiff
+			case "a", "all":
+				// Add current and all remaining differences
+				toUpdate = append(toUpdate, differences[i:]...)
+				return toUpdate, nil
+			case "s", "show":
+				if masked {
+					printDifferenceValues(diff, remoteName, true)
+					continue
+				}
+				fmt.Println("Invalid input. Please enter y(es), n(o), a(ll), or c(ancel).")
+			case "c", "cancel":
+				return toUpdate, nil
+			default:
+				if masked {
+					fmt.Println("Invalid input. Please enter y(es), n(o), a(ll), s(how), or c(ancel).")
+				} else {
+					fmt.Println("Invalid input. Please enter y(es), n(o), a(ll), or c(ancel).")
+				}
+			}
+		}
+	nextDiff:
+	}
+
+	return toUpdate, nil
+}
+
+// printDifferenceValues prints the local and remote values of a difference, masked unless show is set
+func printDifferenceValues(diff Difference, remoteName string, show bool) {
+	if diff.LocalVal == "" {
+		fmt.Printf("   Local:  (not set)\n")
+	} else {
+		fmt.Printf("   Local:  %s\n", displayValue(diff.LocalVal, show))
+	}
+	if !diff.ExistsRemote {
+		fmt.Printf("   %-8s(not set)\n", remoteName+":")
+	} else {
+		fmt.Printf("   %-8s%s\n", remoteName+":", displayValue(diff.RemoteVal, show))
+	}
+}
+
+// displayValue returns value in plaintext when show is set, otherwise its masked form
+func displayValue(value string, show bool) string {
+	if show {
+		return value
+	}
+	return maskValue(value)
+}
+
+// flagWasSet reports whether a command-line flag was given explicitly
+func flagWasSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// maskValue describes a secret without revealing it: its length and a short SHA-256 prefix,
+// which is enough to tell whether two values are the same
+func maskValue(value string) string {
+	if value == "" {
+		return "(empty)"
+	}
+	sum := sha256.Sum256([]byte(value))
+	return fmt.Sprintf("******** (%d chars, sha256:%s)", utf8.RuneCountInString(value), hex.EncodeToString(sum[:])[:8])
+}
+
+func main() {
+	// "envchanter exec [flags] -- command" runs a command with the secrets in its environment,
+	// "envchanter render [flags]" fills in a config file template with them and
+	// "envchanter restore [flags]" rolls the .env file back to its latest backup and
+	// "envchanter lint [flags]" checks the mapping and .env files for conflicting definitions
+	args := os.Args[1:]
+	execMode := len(args) > 0 && args[0] == "exec"
+	renderMode := len(args) > 0 && args[0] == "render"
+	restoreMode := len(args) > 0 && args[0] == "restore"
+	lintMode := len(args) > 0 && args[0] == "lint"
+	if execMode || renderMode || restoreMode || lintMode {
+		args = args[1:]
+	}
+
+	// Define command-line flags
+	mapFile := flag.String("map", "", "Path to JSON, YAML or TOML file mapping env vars to SSM parameter paths or Azure secret names")
+	envFile := flag.String("env", ".env", "Path to .env file (for pull: output file, or - for stdout; for push: input file)")
+	profile := flag.String("profile", "", "AWS profile to use")
+	region := flag.String("region", "", "AWS region to use")
+	showVersion := flag.Bool("version", false, "Show version information")
+	push := flag.Bool("push", false, "Push mode: upload local .env to SSM")
+	sync := flag.Bool("sync", false, "Sync mode: compare .env with SSM and update differences")
+	force := flag.Bool("force", false, "Force mode: update all differences without prompting (only with --sync)")
+	key := flag.String("key", "", "Single environment variable name to push (only with --push)")
+	value := flag.String("value", "", "Value of the single environment variable to push (only with --push)")
+	ssmPath := flag.String("ssm-path", "", "SSM path, or sm:secret-name for Secrets Manager, for the single environment variable (only with --push and AWS)")
+	secretName := flag.String("secret-name", "", "Azure Key Vault secret name for the single environment variable (only with --push and --azure)")
+	vaultPath := flag.String("vault-path", "", "Vault path, optionally with #field, for the single environment variable (only with --push and --vault)")
+	quotes := flag.Bool("quotes", false, "Always quote values in the .env file output")
+	format := flag.String("format", formatDotenv, "Output format for pull: dotenv, export, fish, powershell, json, yaml, docker, systemd, k8s-secret, github or gitlab")
+	k8sName := flag.String("k8s-name", "", "Name of the Kubernetes Secret (required with --format k8s-secret)")
+	k8sNamespace := flag.String("k8s-namespace", "", "Namespace of the Kubernetes Secret (only with --format k8s-secret)")
+	k8sLabels := labelFlags{}
+	flag.Var(k8sLabels, "k8s-label", "Label for the Kubernetes Secret, as key=value (can be repeated, only with --format k8s-secret)")
+	k8sType := flag.String("k8s-type", defaultK8sSecretType, "Type of the Kubernetes Secret (only with --format k8s-secret)")
+	azure := flag.Bool("azure", false, "Use Azure Key Vault instead of AWS SSM")
+	secretsManager := flag.Bool("secrets-manager", false, "Use AWS Secrets Manager instead of SSM for every entry (entries starting with sm: always use it)")
+	vault := flag.Bool("vault", false, "Use HashiCorp Vault instead of AWS SSM")
+	vaultAddr := flag.String("vault-addr", os.Getenv("VAULT_ADDR"), "Vault server address, e.g. https://vault.example.com:8200 (required with --vault, defaults to $VAULT_ADDR)")
+	vaultMount := flag.String("vault-mount", "secret", "Path the Vault KV secrets engine is mounted at (only with --vault)")
+	vaultKVVersion := flag.Int("vault-kv-version", 2, "Version of the Vault KV secrets engine, 1 or 2 (only with --vault)")
+	vaultRoleID := flag.String("vault-role-id", "", "AppRole role ID to log in to Vault with, together with $VAULT_SECRET_ID, instead of $VAULT_TOKEN (only with --vault, defaults to $VAULT_ROLE_ID)")
+	vaultName := flag.String("vault-name", "", "Azure Key Vault name (only with --azure, where it is required)")
+	concurrency := flag.Int("concurrency", 10, "Maximum number of parameters to fetch in parallel")
+	showValues := flag.Bool("show-values", false, "Show secret values in plaintext in sync, check and dry-run output instead of masking them")
+	prefix := flag.String("prefix", "", "SSM path or Azure secret name prefix to pull in full, deriving env var names from the remaining path (pull and sync only)")
+
+	direction := flag.String("direction", syncRemoteToLocal, "Sync direction: remote-to-local updates the .env file, local-to-remote pushes selected local values (only with --sync)")
+	check := flag.Bool("check", false, "Check mode: compare .env with the remote values without prompting or writing, exiting non-zero on drift")
+	checkFormat := flag.String("check-format", "text", "Report format for --check: text or json")
+	dryRun := flag.Bool("dry-run", false, "Show what a push would create, update or leave unchanged without writing anything (only with --push)")
+	mapVarFlags := varFlags{}
+	flag.Var(mapVarFlags, "var", "Set a ${name} placeholder in mapping paths, as name=value (can be repeated)")
+	templateFile := flag.String("template", "", "Path to a Go text/template file to fill in with the parameters (only with render)")
+	renderOutput := flag.String("output", "", "Path to write the rendered template to, or - for stdout (only with render)")
+	toStdout := flag.Bool("stdout", false, "Write the pulled parameters to stdout instead of a file (same as --env -)")
+	varsFile := flag.String("vars-file", "", "Path to a file of name=value lines setting ${name} placeholders in mapping paths")
+	var backup backupMode
+	flag.Var(&backup, "backup", "Keep the previous version of a replaced file as FILE.bak, or as FILE.<timestamp>.bak with --backup=timestamp (pull, sync and render)")
+
+	flag.CommandLine.Parse(args)
+
+	if *toStdout {
+		*envFile = stdoutFile
+	}
+
+	// Keep stdout clean for machine-readable reports, output written to stdout and the output of
+	// an exec'd command
+	if execMode || (*check && *checkFormat == "json") || *envFile == stdoutFile || (renderMode && *renderOutput == stdoutFile) {
+		statusOut = os.Stderr
+	}
+
+	if !execMode {
+		// Print ASCII artwork (skipped in exec mode so the child owns the output)
+		fmt.Fprint(statusOut, asciiArt)
+	}
+
+	if *showVersion {
+		fmt.Printf("EnvChanter %s\n", version)
+		os.Exit(0)
+	}
+
+	// Validate flags based on mode
+	if execMode {
+		if *push || *sync {
+			fmt.Fprintln(statusOut, "Error: exec cannot be combined with --push or --sync")
+			fmt.Fprintln(statusOut, "\nUsage: envchanter exec [flags] -- command [args...]")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+		if *mapFile == "" && *prefix == "" {
+			fmt.Fprintln(statusOut, "Error: For exec mode, --map or --prefix is required")
+			fmt.Fprintln(statusOut, "\nUsage: envchanter exec [flags] -- command [args...]")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+		if flag.NArg() == 0 {
+			fmt.Fprintln(statusOut, "Error: For exec mode, a command is required after --")
+			fmt.Fprintln(statusOut, "\nUsage: envchanter exec [flags] -- command [args...]")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+	}
+
+	if renderMode {
+		if *push || *sync || *check {
+			fmt.Fprintln(statusOut, "Error: render cannot be combined with --push, --sync or --check")
+			fmt.Fprintln(statusOut, "\nUsage: envchanter render [flags]")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+		if *mapFile == "" && *prefix == "" {
+			fmt.Fprintln(statusOut, "Error: For render mode, --map or --prefix is required")
+			fmt.Fprintln(statusOut, "\nUsage: envchanter render [flags]")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+		if *templateFile == "" || *renderOutput == "" {
+			fmt.Fprintln(statusOut, "Error: For render mode, --template and --output are required")
+			fmt.Fprintln(statusOut, "\nUsage: envchanter render [flags]")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+	} else if *templateFile != "" || *renderOutput != "" {
+		fmt.Fprintln(statusOut, "Error: --template and --output can only be used with render")
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if restoreMode {
+		if *push || *sync || *check || *envFile == stdoutFile {
+			fmt.Fprintln(statusOut, "Error: restore cannot be combined with --push, --sync, --check or --stdout")
+			fmt.Fprintln(statusOut, "\nUsage: envchanter restore [--env FILE]")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+	}
+
+	if lintMode {
+		if *push || *sync || *check || *envFile == stdoutFile {
+			fmt.Fprintln(statusOut, "Error: lint cannot be combined with --push, --sync, --check or --stdout")
+			fmt.Fprintln(statusOut, "\nUsage: envchanter lint --map FILE [--env FILE]")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+		if *mapFile == "" {
+			fmt.Fprintln(statusOut, "Error: For lint mode, --map is required")
+			fmt.Fprintln(statusOut, "\nUsage: envchanter lint --map FILE [--env FILE]")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+	}
+
+	if backup != backupNone && (execMode || restoreMode || lintMode || *push || *check) {
+		fmt.Fprintln(statusOut, "Error: --backup can only be used when pulling, syncing or rendering")
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if restoreMode {
+		// Restore mode: no remote access needed, roll back to the latest backup
+		restored, err := restoreBackup(*envFile)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error restoring %s: %v\n", *envFile, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(statusOut, "✓ Restored %s from %s\n", *envFile, restored)
+		return
+	}
+
+	if *check {
+		if execMode || *push || *sync {
+			fmt.Fprintln(statusOut, "Error: --check cannot be combined with exec, --push or --sync")
+			fmt.Fprintln(statusOut, "\nUsage:")
+			flag.PrintDefaults()
+			os.Exit(exitError)
+		}
+		if (*mapFile == "" && *prefix == "") || *envFile == "" {
+			fmt.Fprintln(statusOut, "Error: For check mode, --env and either --map or --prefix are required")
+			fmt.Fprintln(statusOut, "\nUsage:")
+			flag.PrintDefaults()
+			os.Exit(exitError)
+		}
+		if *checkFormat != "text" && *checkFormat != "json" {
+			fmt.Fprintln(statusOut, "Error: --check-format must be text or json")
+			fmt.Fprintln(statusOut, "\nUsage:")
+			flag.PrintDefaults()
+			os.Exit(exitError)
+		}
+	}
+
+	if *direction != syncRemoteToLocal && *direction != syncLocalToRemote {
+		fmt.Fprintf(statusOut, "Error: --direction must be %s or %s\n", syncRemoteToLocal, syncLocalToRemote)
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if _, ok := outputFormats[*format]; !ok {
+		fmt.Fprintf(statusOut, "Error: unknown --format %q\n", *format)
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if *format != formatDotenv && (execMode || renderMode || *push || *sync || *check) {
+		fmt.Fprintln(statusOut, "Error: --format can only be used when pulling")
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if *format == formatK8sSecret {
+		if *k8sName == "" {
+			fmt.Fprintln(statusOut, "Error: --k8s-name is required with --format k8s-secret")
+			fmt.Fprintln(statusOut, "\nUsage:")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+	} else if *k8sName != "" || *k8sNamespace != "" || len(k8sLabels) > 0 || *k8sType != defaultK8sSecretType {
+		fmt.Fprintln(statusOut, "Error: --k8s-name, --k8s-namespace, --k8s-label and --k8s-type can only be used with --format k8s-secret")
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if *envFile == stdoutFile && (execMode || renderMode || *push || *sync || *check) {
+		fmt.Fprintln(statusOut, "Error: --env - and --stdout can only be used when pulling")
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	// The ::add-mask:: commands go to stdout, where they would mix with the variables
+	if *envFile == stdoutFile && *format == formatGitHub {
+		fmt.Fprintln(statusOut, "Error: --format github cannot be used with --env - or --stdout")
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	// Output written to stdout or appended to a file replaces nothing, so there is nothing to back up
+	if backup != backupNone && (*envFile == stdoutFile || *format == formatGitHub || (renderMode && *renderOutput == stdoutFile)) {
+		fmt.Fprintln(statusOut, "Error: --backup cannot be used when writing to stdout or with --format github")
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if *dryRun && !*push {
+		fmt.Fprintln(statusOut, "Error: --dry-run can only be used with --push")
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if *push && *sync {
+		fmt.Fprintln(statusOut, "Error: Cannot use --push and --sync together")
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if *concurrency < 1 {
+		fmt.Fprintln(statusOut, "Error: --concurrency must be at least 1")
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	backends := 0
+	for _, selected := range []bool{*azure, *secretsManager, *vault} {
+		if selected {
+			backends++
+		}
+	}
+	if backends > 1 {
+		fmt.Fprintln(statusOut, "Error: Use only one of --azure, --secrets-manager and --vault")
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	// Vault-specific validation
+	if *vault {
+		if *vaultAddr == "" {
+			fmt.Fprintln(statusOut, "Error: --vault-addr or $VAULT_ADDR is required when using --vault")
+			fmt.Fprintln(statusOut, "\nUsage:")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+		if *vaultKVVersion != 1 && *vaultKVVersion != 2 {
+			fmt.Fprintln(statusOut, "Error: --vault-kv-version must be 1 or 2")
+			fmt.Fprintln(statusOut, "\nUsage:")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+	}
+
+	// Azure-specific validation
+	if *azure {
+		if *vaultName == "" {
+			fmt.Fprintln(statusOut, "Error: --vault-name is required when using --azure")
+			fmt.Fprintln(statusOut, "\nUsage:")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+	} else if *vaultName != "" {
+		// --vault-name names an Azure Key Vault, not a HashiCorp Vault server
+		fmt.Fprintln(statusOut, "Error: --vault-name can only be used with --azure")
+		fmt.Fprintln(statusOut, "\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if *push {
+		// Push mode validation
+		if *prefix != "" {
+			fmt.Fprintln(statusOut, "Error: --prefix cannot be used with --push")
+			fmt.Fprintln(statusOut, "\nUsage:")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+
+		if *key != "" || *value != "" || *ssmPath != "" || *secretName != "" || *vaultPath != "" {
+			// Single parameter push mode
+			if *vault {
+				// Vault single parameter push
+				if *key == "" || *value == "" || *vaultPath == "" {
+					fmt.Fprintln(statusOut, "Error: For Vault single parameter push, all of --key, --value, and --vault-path are required")
+					fmt.Fprintln(statusOut, "\nUsage:")
+					flag.PrintDefaults()
+					os.Exit(1)
+				}
+			} else if *azure {
+				// Azure single parameter push
+				if *key == "" || *value == "" || *secretName == "" {
+					fmt.Fprintln(statusOut, "Error: For Azure single parameter push, all of --key, --value, and --secret-name are required")
+					fmt.Fprintln(statusOut, "\nUsage:")
+					flag.PrintDefaults()
+					os.Exit(1)
+				}
+			} else {
+				// AWS single parameter push
+				if *key == "" || *value == "" || *ssmPath == "" {
+					fmt.Fprintln(statusOut, "Error: For AWS single parameter push, all of --key, --value, and --ssm-path are required")
+					fmt.Fprintln(statusOut, "\nUsage:")
+					flag.PrintDefaults()
+					os.Exit(1)
+				}
+			}
+		} else {
+			// File-based push mode
+			if *mapFile == "" || *envFile == "" {
+				fmt.Fprintln(statusOut, "Error: For file-based push, both --map and --env are required")
+				fmt.Fprintln(statusOut, "\nUsage:")
+				flag.PrintDefaults()
+				os.Exit(1)
+			}
+		}
+	} else if *sync {
+		// Sync mode validation
+		if (*mapFile == "" && *prefix == "") || *envFile == "" {
+			fmt.Fprintln(statusOut, "Error: For sync mode, --env and either --map or --prefix are required")
+			fmt.Fprintln(statusOut, "\nUsage:")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+	} else {
+		// Pull mode validation (existing behavior)
+		if *mapFile == "" && *prefix == "" {
+			fmt.Fprintln(statusOut, "Error: --map or --prefix flag is required")
+			fmt.Fprintln(statusOut, "\nUsage:")
+			flag.PrintDefaults()
+			os.Exit(1)
+		}
+	}
+
+	// Collect the values for ${name} placeholders in mapping paths
+	mapVars, err := loadMapVars(mapVarFlags, *varsFile)
+	if err != nil {
+		fmt.Fprintf(statusOut, "Error loading variables: %v\n", err)
+		os.Exit(1)
+	}
+
+	if lintMode {
+		// Lint mode: check the files locally without remote access. The default .env file is
+		// only checked when it exists, so a map can be linted on its own.
+		lintEnv := *envFile
+		if _, err := os.Stat(lintEnv); err != nil && !flagWasSet("env") {
+			lintEnv = ""
+		}
+
+		issues, err := lintFiles(*mapFile, lintEnv, mapVars)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, issue := range issues {
+			fmt.Fprintln(statusOut, issue)
+		}
+		if len(issues) > 0 {
+			fmt.Fprintf(statusOut, "\n✗ Found %d problem(s)\n", len(issues))
+			os.Exit(1)
+		}
+
+		checked := *mapFile
+		if lintEnv != "" {
+			checked += " and " + lintEnv
+		}
+		fmt.Fprintf(statusOut, "✓ No problems found in %s\n", checked)
+		return
+	}
+
+	ctx := context.Background()
+
+	// Create the secret provider (Azure Key Vault, HashiCorp Vault or AWS)
+	providerOpts := providerOptions{
+		Azure:          *azure,
+		VaultName:      *vaultName,
+		Vault:          *vault,
+		SecretsManager: *secretsManager,
+		Profile:        *profile,
+		Region:         *region,
+	}
+	// Vault credentials are only read when Vault is in use
+	if *vault {
+		// Not the flag default, so that usage output never prints the role ID
+		if *vaultRoleID == "" {
+			*vaultRoleID = os.Getenv("VAULT_ROLE_ID")
+		}
+		providerOpts.VaultConfig = vaultConfig{
+			Address:   *vaultAddr,
+			Mount:     *vaultMount,
+			KVVersion: *vaultKVVersion,
+			Namespace: os.Getenv("VAULT_NAMESPACE"),
+			Token:     vaultToken(),
+			RoleID:    *vaultRoleID,
+			SecretID:  os.Getenv("VAULT_SECRET_ID"),
+		}
+	}
+	provider, err := newProvider(ctx, providerOpts)
+	if err != nil {
+		fmt.Fprintf(statusOut, "Error creating secret provider: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *check {
+		// Check mode: report drift through the exit code without prompting or writing
+		report, code := checkParameters(ctx, provider, *mapFile, *prefix, mapVars, *envFile, *concurrency, *showValues)
+		if err := writeCheckReport(os.Stdout, report, *checkFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing check report: %v\n", err)
+			os.Exit(exitError)
+		}
+		os.Exit(code)
+	} else if execMode {
+		// Exec mode: inject the secrets into a child process without writing them to disk
+		paramMap, specs, provider, err := resolveParameterMap(ctx, provider, *mapFile, *prefix, mapVars)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error loading parameter map: %v\n", err)
+			os.Exit(1)
+		}
+
+		envVars, err := fetchParameters(ctx, provider, paramMap, *concurrency)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error fetching parameters: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := applyParameterSpecs(envVars, specs); err != nil {
+			fmt.Fprintf(statusOut, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		code, err := runCommand(flag.Args(), mergeEnv(os.Environ(), envVars))
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error running command: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(code)
+	} else if renderMode {
+		// Render mode: fill in a config file template with the secrets
+		paramMap, specs, provider, err := resolveParameterMap(ctx, provider, *mapFile, *prefix, mapVars)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error loading parameter map: %v\n", err)
+			os.Exit(1)
+		}
+
+		envVars, err := fetchParameters(ctx, provider, paramMap, *concurrency)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error fetching parameters: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := applyParameterSpecs(envVars, specs); err != nil {
+			fmt.Fprintf(statusOut, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		rendered, err := renderTemplateFile(*templateFile, envVars)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error rendering template: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := writeOutputData(*renderOutput, rendered, outputOptions{Backup: backup}); err != nil {
+			fmt.Fprintf(statusOut, "Error writing %s: %v\n", *renderOutput, err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(statusOut, "Successfully rendered %s with %d parameters from %s\n", *templateFile, len(envVars), provider.Name())
+	} else if *push {
+		// Push mode
+		if *key != "" {
+			remotePath := *ssmPath
+			if *azure {
+				remotePath = *secretName
+			} else if *vault {
+				remotePath = *vaultPath
+			}
+
+			// Validate key and remote path before pushing
+			if err := provider.ValidateMap(ParameterMap{*key: remotePath}); err != nil {
+				fmt.Fprintf(statusOut, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			useParameterMap(provider, ParameterMap{*key: remotePath})
+
+			if *dryRun {
+				changes, err := planPush(ctx, provider, map[string]string{*key: *value}, ParameterMap{*key: remotePath}, 1)
+				if err != nil {
+					fmt.Fprintf(statusOut, "Error planning push: %v\n", err)
+					os.Exit(1)
+				}
+				printPushPlan(changes, provider.Name(), *showValues)
+				return
+			}
+
+			// Single parameter push
+			changes, err := pushParameters(ctx, provider, map[string]string{*key: *value}, ParameterMap{*key: remotePath}, 1)
+			if err != nil {
+				fmt.Fprintf(statusOut, "Error pushing parameter: %v\n", err)
+				os.Exit(1)
+			}
+			if changes[0].Action == pushUnchanged {
+				fmt.Fprintf(statusOut, "%s is already up to date in %s %s\n", *key, provider.Name(), remotePath)
+			} else {
+				fmt.Fprintf(statusOut, "Successfully pushed %s to %s %s\n", *key, provider.Name(), remotePath)
+			}
+		} else {
+			// File-based push
+			paramMap, _, err := loadParameterMap(*mapFile, provider, mapVars)
+			if err != nil {
+				fmt.Fprintf(statusOut, "Error loading parameter map: %v\n", err)
+				os.Exit(1)
+			}
+			useParameterMap(provider, paramMap)
+
+			envVars, err := readEnvFile(*envFile)
+			if err != nil {
+				fmt.Fprintf(statusOut, "Error reading .env file: %v\n", err)
+				os.Exit(1)
+			}
+
+			if *dryRun {
+				changes, err := planPush(ctx, provider, envVars, paramMap, *concurrency)
+				if err != nil {
+					fmt.Fprintf(statusOut, "Error planning push: %v\n", err)
+					os.Exit(1)
+				}
+				printPushPlan(changes, provider.Name(), *showValues)
+				return
+			}
+
+			changes, err := pushParameters(ctx, provider, envVars, paramMap, *concurrency)
+			if err != nil {
+				fmt.Fprintf(statusOut, "Error pushing parameters: %v\n", err)
+				os.Exit(1)
+			}
+			created, updated, unchanged := countPushChanges(changes)
+			fmt.Fprintf(statusOut, "Successfully pushed to %s: %d created, %d updated, %d unchanged\n", provider.Name(), created, updated, unchanged)
+		}
+	} else if *sync {
+		// Sync mode
+		paramMap, specs, provider, err := resolveParameterMap(ctx, provider, *mapFile, *prefix, mapVars)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error loading parameter map: %v\n", err)
+			os.Exit(1)
+		}
+
+		localEnvVars, err := readEnvFile(*envFile)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error reading .env file: %v\n", err)
+			os.Exit(1)
+		}
+
+		opts := syncOptions{
+			Force:       *force,
+			Quotes:      *quotes,
+			Concurrency: *concurrency,
+			ShowValues:  *showValues,
+			Direction:   *direction,
+			Backup:      backup,
+		}
+		err = syncParameters(ctx, provider, localEnvVars, paramMap, specs, *envFile, opts)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error syncing parameters: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		// Pull mode (existing behavior)
+		paramMap, specs, provider, err := resolveParameterMap(ctx, provider, *mapFile, *prefix, mapVars)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error loading parameter map: %v\n", err)
+			os.Exit(1)
+		}
+
+		envVars, err := fetchParameters(ctx, provider, paramMap, *concurrency)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error fetching parameters: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := applyParameterSpecs(envVars, specs); err != nil {
+			fmt.Fprintf(statusOut, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		outputFile := *envFile
+		if *format == formatGitHub {
+			// Append to the runner's environment file unless --env names another file,
+			// and have the runner redact every value from the logs
+			if !flagWasSet("env") {
+				outputFile = os.Getenv(githubEnvVar)
+				if outputFile == "" {
+					fmt.Fprintf(statusOut, "Error: $%s is not set; use --format github inside GitHub Actions or pass --env\n", githubEnvVar)
+					os.Exit(1)
+				}
+			}
+			if err := writeGitHubMasks(os.Stdout, envVars); err != nil {
+				fmt.Fprintf(statusOut, "Error masking values: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		opts := outputOptions{
+			Quotes:       *quotes,
+			Append:       *format == formatGitHub,
+			K8sName:      *k8sName,
+			K8sNamespace: *k8sNamespace,
+			K8sLabels:    k8sLabels,
+			K8sType:      *k8sType,
+			Backup:       backup,
+		}
+		err = writeOutputFile(outputFile, *format, envVars, opts)
+		if err != nil {
+			fmt.Fprintf(statusOut, "Error writing %s: %v\n", outputFile, err)
+			os.Exit(1)
+		}
+
+		if outputFile == stdoutFile {
+			fmt.Fprintf(statusOut, "Successfully wrote %d parameters from %s to stdout\n", len(envVars), provider.Name())
+		} else {
+			fmt.Fprintf(statusOut, "Successfully generated %s with %d parameters from %s\n", outputFile, len(envVars), provider.Name())
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// decodeMapFile decodes the entries of a mapping file in the format given by its extension:
+// .yaml/.yml for YAML, .toml for TOML and JSON otherwise
+func decodeMapFile(filename string, data []byte) (map[string]any, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return decodeYAMLMap(data)
+	case ".toml":
+		return decodeTOMLMap(data)
+	default:
+		return decodeJSONMap(data)
+	}
+}
+
+// decodeJSONMap decodes a JSON mapping, reporting the line of syntax and type errors
+func decodeJSONMap(data []byte) (map[string]any, error) {
+	var entries map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&entries); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			return nil, fmt.Errorf("failed to parse JSON at line %d: %w", lineAt(data, syntaxErr.Offset), err)
+		case errors.As(err, &typeErr):
+			return nil, fmt.Errorf("failed to parse JSON at line %d: %w", lineAt(data, typeErr.Offset), err)
+		}
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	// Like json.Unmarshal, reject anything after the mapping
+	rest := data[decoder.InputOffset():]
+	if err := decoder.Decode(new(json.RawMessage)); !errors.Is(err, io.EOF) {
+		offset := int64(len(data) - len(bytes.TrimLeft(rest, " \t\r\n")))
+		return nil, fmt.Errorf("failed to parse JSON at line %d: unexpected content after the mapping", lineAt(data, offset))
+	}
+	return entries, nil
+}
+
+// decodeYAMLMap decodes a YAML mapping. YAML errors already name the line.
+func decodeYAMLMap(data []byte) (map[string]any, error) {
+	var entries map[string]any
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	return entries, nil
+}
+
+// decodeTOMLMap decodes a TOML mapping. TOML parse errors already name the line.
+func decodeTOMLMap(data []byte) (map[string]any, error) {
+	var entries map[string]any
+	if _, err := toml.Decode(string(data), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse TOML: %w", err)
+	}
+	return entries, nil
+}
+
+// lineAt returns the 1-based line number of a byte offset in data
+func lineAt(data []byte, offset int64) int {
+	offset = min(max(offset, 0), int64(len(data)))
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadParameterMapFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"map.yaml", `
+# Owned by the platform team
+API_KEY: /myapp/dev/api-key
+DB_PORT:
+  path: /myapp/dev/db-port
+  default: 5432
+  type: int
+`},
+		{"map.yml", `
+API_KEY: /myapp/dev/api-key # Owned by the platform team
+DB_PORT: {path: /myapp/dev/db-port, default: 5432, type: int}
+`},
+		{"map.toml", `
+# Owned by the platform team
+API_KEY = "/myapp/dev/api-key"
+DB_PORT = { path = "/myapp/dev/db-port", default = 5432, type = "int" }
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapFile := filepath.Join(t.TempDir(), tt.name)
+			if err := os.WriteFile(mapFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			paramMap, specs, err := loadParameterMap(mapFile, &ssmProvider{}, nil)
+			if err != nil {
+				t.Fatalf("loadParameterMap() error = %v", err)
+			}
+
+			if paramMap["API_KEY"] != "/myapp/dev/api-key" || paramMap["DB_PORT"] != "/myapp/dev/db-port" {
+				t.Errorf("Unexpected parameter map: %v", paramMap)
+			}
+			if def := specs["DB_PORT"].Default; def == nil || *def != "5432" {
+				t.Errorf("Expected DB_PORT default 5432, got %+v", specs["DB_PORT"])
+			}
+		})
+	}
+}
+
+func TestLoadParameterMapParseErrorLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    string
+	}{
+		{"map.json", "{\n  \"API_KEY\": \"/myapp/dev/api-key\",\n  \"DB_PASSWORD\" \"/myapp/dev/db-password\"\n}", "line 3"},
+		{"trailing.json", "{\"API_KEY\": \"/myapp/dev/api-key\"}\ngarbage\n", "line 2"},
+		{"concatenated.json", "{\"API_KEY\": \"/myapp/dev/api-key\"}\n\n{\"DB_PASSWORD\": \"/myapp/dev/db-password\"}\n", "line 3"},
+		{"map.yaml", "API_KEY: /myapp/dev/api-key\n\tDB_PASSWORD: /myapp/dev/db-password\n", "line 2"},
+		{"map.toml", "API_KEY = \"/myapp/dev/api-key\"\nDB_PASSWORD = /myapp/dev/db-password\n", "line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapFile := filepath.Join(t.TempDir(), tt.name)
+			if err := os.WriteFile(mapFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			_, _, err := loadParameterMap(mapFile, &ssmProvider{}, nil)
+			if err == nil {
+				t.Fatal("Expected parse error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.line) {
+				t.Errorf("Expected error to include %q, got %v", tt.line, err)
+			}
+		})
+	}
+}
+
+func TestLoadParameterMapValidatesYAML(t *testing.T) {
+	mapFile := filepath.Join(t.TempDir(), "map.yaml")
+	if err := os.WriteFile(mapFile, []byte("API_KEY: myapp/dev/api-key\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if _, _, err := loadParameterMap(mapFile, &ssmProvider{}, nil); err == nil {
+		t.Error("Expected validation error for SSM path without leading /, got nil")
+	}
+}
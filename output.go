@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats for pulled parameters
+const (
+	formatDotenv     = "dotenv"
+	formatExport     = "export"
+	formatFish       = "fish"
+	formatPowerShell = "powershell"
+	formatJSON       = "json"
+	formatYAML       = "yaml"
+	formatDocker     = "docker"
+	formatSystemd    = "systemd"
+)
+
+// outputOptions holds the settings that affect how parameters are rendered
+type outputOptions struct {
+	// Quotes always quotes values in dotenv output
+	Quotes bool
+
+	// Append adds to the end of an existing file instead of replacing it
+	Append bool
+
+	// Backup keeps the previous version of a file that is replaced
+	Backup backupMode
+
+	// Name, namespace, labels and type of a Kubernetes Secret manifest
+	K8sName      string
+	K8sNamespace string
+	K8sLabels    map[string]string
+	K8sType      string
+}
+
+// outputFormatter renders environment variables, sorted by key, in one output format
+type outputFormatter func(envVars map[string]string, opts outputOptions) ([]byte, error)
+
+// outputFormats maps each --format name to its formatter
+var outputFormats = map[string]outputFormatter{
+	formatDotenv:     renderDotenv,
+	formatExport:     renderExport,
+	formatFish:       renderFish,
+	formatPowerShell: renderPowerShell,
+	formatJSON:       renderJSON,
+	formatYAML:       renderYAML,
+	formatDocker:     renderDocker,
+	formatSystemd:    renderSystemd,
+	formatK8sSecret:  renderK8sSecret,
+	formatGitHub:     renderGitHubEnv,
+	formatGitLab:     renderGitLabDotenv,
+}
+
+// stdoutFile is the --env value that writes the output to stdout instead of a file
+const stdoutFile = "-"
+
+// dataOut receives the output written to stdoutFile
+var dataOut io.Writer = os.Stdout
+
+// writeOutputFile renders environment variables in the given format and writes them to filename,
+// or to stdout when filename is stdoutFile. The output is rendered in full before the file is
+// opened, so a value the format cannot represent leaves any existing file untouched.
+func writeOutputFile(filename, format string, envVars map[string]string, opts outputOptions) error {
+	render, ok := outputFormats[format]
+	if !ok {
+		return fmt.Errorf("unknown output format %q", format)
+	}
+	data, err := render(envVars, opts)
+	if err != nil {
+		return err
+	}
+
+	return writeOutputData(filename, data, opts)
+}
+
+// writeOutputData writes rendered output to filename with restrictive permissions, or to stdout
+// when filename is stdoutFile. Unless appending, the file is replaced atomically, after backing up
+// the previous version if opts.Backup is set.
+func writeOutputData(filename string, data []byte, opts outputOptions) error {
+	if filename == stdoutFile {
+		if _, err := dataOut.Write(data); err != nil {
+			return fmt.Errorf("failed to write to stdout: %w", err)
+		}
+		return nil
+	}
+
+	// Validate filename to prevent path traversal
+	if err := validateFilePath(filename); err != nil {
+		return fmt.Errorf("invalid file path: %w", err)
+	}
+
+	if opts.Append {
+		// Create file with restrictive permissions (0600 = owner read/write only)
+		file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+		defer file.Close()
+
+		if _, err := file.Write(data); err != nil {
+			return fmt.Errorf("failed to write to file: %w", err)
+		}
+		return nil
+	}
+
+	if err := backupFile(filename, opts.Backup, time.Now()); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filename, data)
+}
+
+// writeFileAtomic replaces filename with data by writing a temporary file in the same directory,
+// syncing it to disk and renaming it over the target, so an interrupted write never leaves a
+// truncated file behind. A symlinked target is followed and the file it points to is replaced.
+func writeFileAtomic(filename string, data []byte) error {
+	if resolved, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = resolved
+	}
+
+	// CreateTemp uses restrictive permissions (0600 = owner read/write only)
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	tmpName := tmp.Name()
+	renamed := false
+	defer func() {
+		if !renamed {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write to file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write to file: %w", err)
+	}
+
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	renamed = true
+
+	return nil
+}
+
+// renderDotenv renders KEY=value lines, double-quoting values that need it
+func renderDotenv(envVars map[string]string, opts outputOptions) ([]byte, error) {
+	var b bytes.Buffer
+	for _, key := range sortedKeys(envVars) {
+		fmt.Fprintf(&b, "%s=%s\n", key, formatDotenvValue(envVars[key], opts.Quotes))
+	}
+	return b.Bytes(), nil
+}
+
+// formatDotenvValue formats a value for a .env file, double-quoting it when alwaysQuote is set
+// or when it would not read back unchanged otherwise
+func formatDotenvValue(value string, alwaysQuote bool) string {
+	if alwaysQuote || needsQuoting(value) {
+		return fmt.Sprintf("\"%s\"", escapeValue(value))
+	}
+	return value
+}
+
+// renderExport renders POSIX shell export statements. Values are single-quoted, which keeps
+// everything literal; a single quote is written as '\'' (close, escaped quote, reopen).
+func renderExport(envVars map[string]string, opts outputOptions) ([]byte, error) {
+	var b bytes.Buffer
+	for _, key := range sortedKeys(envVars) {
+		fmt.Fprintf(&b, "export %s='%s'\n", key, strings.ReplaceAll(envVars[key], "'", `'\''`))
+	}
+	return b.Bytes(), nil
+}
+
+// renderFish renders fish shell set statements. Inside fish single quotes only \ and ' are special.
+func renderFish(envVars map[string]string, opts outputOptions) ([]byte, error) {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	var b bytes.Buffer
+	for _, key := range sortedKeys(envVars) {
+		fmt.Fprintf(&b, "set -gx %s '%s'\n", key, replacer.Replace(envVars[key]))
+	}
+	return b.Bytes(), nil
+}
+
+// powerShellQuoteEscaper doubles every character PowerShell accepts as a single quote: the ASCII
+// quote and the typographic quotes U+2018 to U+201B
+var powerShellQuoteEscaper = strings.NewReplacer(
+	"'", "''",
+	"\u2018", "\u2018\u2018",
+	"\u2019", "\u2019\u2019",
+	"\u201A", "\u201A\u201A",
+	"\u201B", "\u201B\u201B",
+)
+
+// renderPowerShell renders PowerShell assignments. Inside PowerShell single quotes only the
+// quote characters are special and are doubled.
+func renderPowerShell(envVars map[string]string, opts outputOptions) ([]byte, error) {
+	var b bytes.Buffer
+	for _, key := range sortedKeys(envVars) {
+		fmt.Fprintf(&b, "$env:%s = '%s'\n", key, powerShellQuoteEscaper.Replace(envVars[key]))
+	}
+	return b.Bytes(), nil
+}
+
+// renderJSON renders a JSON object of keys to values
+func renderJSON(envVars map[string]string, opts outputOptions) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(envVars); err != nil {
+		return nil, fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// renderYAML renders a YAML mapping of keys to values. Values that would otherwise be read as
+// another type, such as "true" or "5432", are quoted to keep them strings.
+func renderYAML(envVars map[string]string, opts outputOptions) ([]byte, error) {
+	data, err := yaml.Marshal(envVars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return data, nil
+}
+
+// renderDocker renders a Docker --env-file. Docker takes everything after the = literally,
+// including quotes, and has no way to represent a line break.
+func renderDocker(envVars map[string]string, opts outputOptions) ([]byte, error) {
+	var b bytes.Buffer
+	for _, key := range sortedKeys(envVars) {
+		value := envVars[key]
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("docker env-file format cannot represent the multi-line value of %s", key)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", key, value)
+	}
+	return b.Bytes(), nil
+}
+
+// renderSystemd renders a systemd EnvironmentFile. Values are double-quoted with \, ", ` and $
+// escaped; line breaks are kept inside the quotes.
+func renderSystemd(envVars map[string]string, opts outputOptions) ([]byte, error) {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+	var b bytes.Buffer
+	for _, key := range sortedKeys(envVars) {
+		fmt.Fprintf(&b, "%s=\"%s\"\n", key, replacer.Replace(envVars[key]))
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderFormats(t *testing.T) {
+	envVars := map[string]string{
+		"A_QUOTE":  `it's "quoted"`,
+		"B_PORT":   "5432",
+		"C_SHELL":  `$HOME \n`,
+		"D_QUOTES": "a\u2018b\u2019c\u201Ad\u201Be",
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{formatDotenv, "A_QUOTE=\"it's \\\"quoted\\\"\"\nB_PORT=5432\nC_SHELL=\"\\$HOME \\\\n\"\nD_QUOTES=a\u2018b\u2019c\u201Ad\u201Be\n"},
+		{formatExport, "export A_QUOTE='it'\\''s \"quoted\"'\nexport B_PORT='5432'\nexport C_SHELL='$HOME \\n'\nexport D_QUOTES='a\u2018b\u2019c\u201Ad\u201Be'\n"},
+		{formatFish, "set -gx A_QUOTE 'it\\'s \"quoted\"'\nset -gx B_PORT '5432'\nset -gx C_SHELL '$HOME \\\\n'\nset -gx D_QUOTES 'a\u2018b\u2019c\u201Ad\u201Be'\n"},
+		{formatPowerShell, "$env:A_QUOTE = 'it''s \"quoted\"'\n$env:B_PORT = '5432'\n$env:C_SHELL = '$HOME \\n'\n$env:D_QUOTES = 'a\u2018\u2018b\u2019\u2019c\u201A\u201Ad\u201B\u201Be'\n"},
+		{formatDocker, "A_QUOTE=it's \"quoted\"\nB_PORT=5432\nC_SHELL=$HOME \\n\nD_QUOTES=a\u2018b\u2019c\u201Ad\u201Be\n"},
+		{formatSystemd, "A_QUOTE=\"it's \\\"quoted\\\"\"\nB_PORT=\"5432\"\nC_SHELL=\"\\$HOME \\\\n\"\nD_QUOTES=\"a\u2018b\u2019c\u201Ad\u201Be\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := outputFormats[tt.format](envVars, outputOptions{})
+			if err != nil {
+				t.Fatalf("render error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Unexpected %s output:\ngot:\n%s\nwant:\n%s", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderStructuredFormats(t *testing.T) {
+	envVars := map[string]string{
+		"ENABLED":   "true",
+		"PORT":      "5432",
+		"MULTILINE": "line1\nline2",
+		"HTML":      "<a&b>",
+	}
+
+	data, err := renderJSON(envVars, outputOptions{})
+	if err != nil {
+		t.Fatalf("renderJSON() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"<a&b>"`) {
+		t.Errorf("Expected HTML characters to be left unescaped, got %s", data)
+	}
+	var fromJSON map[string]string
+	if err := json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+
+	data, err = renderYAML(envVars, outputOptions{})
+	if err != nil {
+		t.Fatalf("renderYAML() error = %v", err)
+	}
+	var fromYAML map[string]any
+	if err := yaml.Unmarshal(data, &fromYAML); err != nil {
+		t.Fatalf("Failed to parse YAML output: %v", err)
+	}
+
+	for key, want := range envVars {
+		if fromJSON[key] != want {
+			t.Errorf("JSON: for key %s, expected %q, got %q", key, want, fromJSON[key])
+		}
+		if got, ok := fromYAML[key].(string); !ok || got != want {
+			t.Errorf("YAML: for key %s, expected string %q, got %#v", key, want, fromYAML[key])
+		}
+	}
+}
+
+func TestWriteOutputFileDockerMultiline(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "docker.env")
+	if err := os.WriteFile(outFile, []byte("EXISTING=1\n"), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	err := writeOutputFile(outFile, formatDocker, map[string]string{"CERT": "line1\nline2"}, outputOptions{})
+	if err == nil || !strings.Contains(err.Error(), "CERT") {
+		t.Fatalf("Expected error naming CERT, got %v", err)
+	}
+
+	content, _ := os.ReadFile(outFile)
+	if string(content) != "EXISTING=1\n" {
+		t.Errorf("Expected existing file to be left untouched, got %q", content)
+	}
+}
+
+func TestWriteOutputFilePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+
+	for format := range outputFormats {
+		t.Run(format, func(t *testing.T) {
+			outFile := filepath.Join(t.TempDir(), "out")
+			if err := writeOutputFile(outFile, format, map[string]string{"KEY": "value"}, outputOptions{K8sName: "app-secrets"}); err != nil {
+				t.Fatalf("writeOutputFile() error = %v", err)
+			}
+
+			info, err := os.Stat(outFile)
+			if err != nil {
+				t.Fatalf("Failed to stat output file: %v", err)
+			}
+			if perm := info.Mode().Perm(); perm != 0600 {
+				t.Errorf("Expected permissions 0600, got %o", perm)
+			}
+		})
+	}
+}
+
+func TestWriteOutputFileStdout(t *testing.T) {
+	var out bytes.Buffer
+	dataOut = &out
+	defer func() { dataOut = os.Stdout }()
+
+	err := writeOutputFile(stdoutFile, formatExport, map[string]string{"API_KEY": "my-api-key"}, outputOptions{})
+	if err != nil {
+		t.Fatalf("writeOutputFile() error = %v", err)
+	}
+
+	if out.String() != "export API_KEY='my-api-key'\n" {
+		t.Errorf("Unexpected stdout output: %q", out.String())
+	}
+	if _, err := os.Stat(stdoutFile); !os.IsNotExist(err) {
+		t.Errorf("Expected no file named %q to be created", stdoutFile)
+	}
+}
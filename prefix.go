@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+)
+
+// prefixMapKey is the reserved mapping key that names a path prefix to pull in full.
+// It cannot clash with a real entry because "*" is not a valid environment variable name.
+const prefixMapKey = "*"
+
+// deriveEnvVarName derives an environment variable name from a path relative to a prefix,
+// e.g. "db-password" becomes DB_PASSWORD and "database/url" becomes DATABASE_URL
+func deriveEnvVarName(relPath string) (string, error) {
+	relPath = strings.Trim(relPath, "/")
+
+	var b strings.Builder
+	for _, char := range relPath {
+		switch {
+		case char >= 'a' && char <= 'z':
+			b.WriteRune(char - 'a' + 'A')
+		case (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') || char == '_':
+			b.WriteRune(char)
+		case char == '-' || char == '.' || char == '/':
+			b.WriteRune('_')
+		default:
+			return "", fmt.Errorf("cannot derive environment variable name from %q", relPath)
+		}
+	}
+
+	name := b.String()
+	if err := validateEnvVarName(name); err != nil {
+		return "", fmt.Errorf("cannot derive environment variable name from %q: %w", relPath, err)
+	}
+
+	return name, nil
+}
+
+// expandPrefix returns a copy of paramMap in which the prefix (from the flag or the map's "*"
+// entry) is replaced by one entry per secret found under it. Explicit entries in the map take
+// precedence over derived names and claim their path, so a secret can be renamed by mapping it.
+// The values the provider returned with the listing are returned by path.
+func expandPrefix(ctx context.Context, provider SecretProvider, paramMap ParameterMap, prefix string) (ParameterMap, map[string]string, error) {
+	expanded := make(ParameterMap, len(paramMap))
+	var prefixes []string
+	for envKey, path := range paramMap {
+		if envKey == prefixMapKey {
+			prefixes = append(prefixes, path)
+			continue
+		}
+		expanded[envKey] = path
+	}
+	if prefix != "" {
+		prefixes = append(prefixes, prefix)
+	}
+
+	if len(prefixes) == 0 {
+		return expanded, nil, nil
+	}
+
+	// Paths that are mapped explicitly keep their explicit name
+	explicitPaths := make(map[string]bool, len(expanded))
+	for _, path := range expanded {
+		explicitPaths[path] = true
+	}
+
+	derivedFrom := make(map[string]string)
+	listed := make(map[string]string)
+	for _, prefix := range prefixes {
+		paths, values, err := listPrefix(ctx, provider, prefix)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list %s parameters under prefix: %w", provider.Name(), err)
+		}
+		sort.Strings(paths)
+		maps.Copy(listed, values)
+
+		for _, path := range paths {
+			if explicitPaths[path] {
+				continue
+			}
+
+			envKey, err := deriveEnvVarName(strings.TrimPrefix(path, prefix))
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// Explicit entries override derived ones
+			if _, explicit := paramMap[envKey]; explicit {
+				continue
+			}
+
+			if other, exists := derivedFrom[envKey]; exists && other != path {
+				return nil, nil, fmt.Errorf("%s is derived from more than one parameter under the prefix; map one of them explicitly", envKey)
+			}
+			derivedFrom[envKey] = path
+			expanded[envKey] = path
+		}
+	}
+
+	return expanded, listed, nil
+}
+
+// listPrefix lists the secrets under prefix, with their values if the provider returns them
+func listPrefix(ctx context.Context, provider SecretProvider, prefix string) ([]string, map[string]string, error) {
+	if lister, ok := provider.(valueLister); ok {
+		return lister.ListValues(ctx, prefix)
+	}
+	paths, err := provider.List(ctx, prefix)
+	return paths, nil, err
+}
+
+// resolveParameterMap loads the mapping file, if any, and expands any path prefix given in
+// the map or on the command line into individual entries. Placeholders are filled in from vars.
+// The returned provider serves the values already returned by a prefix listing, so fetching
+// the map through it does not read them again.
+func resolveParameterMap(ctx context.Context, provider SecretProvider, mapFile, prefix string, vars map[string]string) (ParameterMap, ParameterSpecs, SecretProvider, error) {
+	paramMap, specs := ParameterMap{}, ParameterSpecs{}
+	if mapFile != "" {
+		var err error
+		paramMap, specs, err = loadParameterMap(mapFile, provider, vars)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if prefix != "" {
+		interpolated, err := interpolateParameterMap(ParameterMap{prefixMapKey: prefix}, vars)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid prefix: %w", err)
+		}
+		prefix = interpolated[prefixMapKey]
+		if err := provider.ValidateMap(ParameterMap{prefixMapKey: prefix}); err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid prefix: %w", err)
+		}
+	}
+
+	expanded, listed, err := expandPrefix(ctx, provider, paramMap, prefix)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if len(expanded) == 0 {
+		return nil, nil, nil, fmt.Errorf("no parameters found under prefix")
+	}
+	useParameterMap(provider, expanded)
+
+	if len(listed) > 0 {
+		provider = &listedValuesProvider{SecretProvider: provider, values: listed}
+	}
+	return expanded, specs, provider, nil
+}
+
+// listedValuesProvider serves the values returned by a prefix listing and reads every other
+// path from the provider it wraps
+type listedValuesProvider struct {
+	SecretProvider
+	values map[string]string
+}
+
+func (p *listedValuesProvider) Get(ctx context.Context, path string) (string, error) {
+	if value, ok := p.values[path]; ok {
+		return value, nil
+	}
+	return p.SecretProvider.Get(ctx, path)
+}
+
+func (p *listedValuesProvider) BatchSize() int {
+	if batcher, ok := p.SecretProvider.(batchGetter); ok {
+		return batcher.BatchSize()
+	}
+	return 1
+}
+
+// GetBatch takes the listed values and fetches only the remaining paths
+func (p *listedValuesProvider) GetBatch(ctx context.Context, paths []string) (map[string]string, error) {
+	values := make(map[string]string, len(paths))
+	var remaining []string
+	for _, path := range paths {
+		if value, ok := p.values[path]; ok {
+			values[path] = value
+		} else {
+			remaining = append(remaining, path)
+		}
+	}
+
+	if len(remaining) > 0 {
+		fetched, err := fetchBatch(ctx, p.SecretProvider, remaining)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(values, fetched)
+	}
+	return values, nil
+}
+
+// PutBatch writes through the wrapped provider, in a single batch if it supports one
+func (p *listedValuesProvider) PutBatch(ctx context.Context, values map[string]string) error {
+	if putter, ok := p.SecretProvider.(batchPutter); ok {
+		return putter.PutBatch(ctx, values)
+	}
+	for _, path := range sortedKeys(values) {
+		if err := p.SecretProvider.Put(ctx, path, values[path]); err != nil {
+			return fmt.Errorf("failed to put %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDeriveEnvVarName(t *testing.T) {
+	tests := []struct {
+		relPath string
+		want    string
+		wantErr bool
+	}{
+		{"db-password", "DB_PASSWORD", false},
+		{"/api-key", "API_KEY", false},
+		{"database/url", "DATABASE_URL", false},
+		{"key.value", "KEY_VALUE", false},
+		{"Already_Upper", "ALREADY_UPPER", false},
+		{"1password", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.relPath, func(t *testing.T) {
+			got, err := deriveEnvVarName(tt.relPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("deriveEnvVarName(%q) error = %v, wantErr %v", tt.relPath, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("deriveEnvVarName(%q) = %q, want %q", tt.relPath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandPrefix(t *testing.T) {
+	provider := newMemProvider(map[string]string{
+		"/myapp/prod/db-password":  "secret",
+		"/myapp/prod/api-key":      "key",
+		"/myapp/prod/legacy-token": "token",
+		"/shared/database-url":     "url",
+	})
+	paramMap := ParameterMap{
+		prefixMapKey:   "/myapp/prod/",
+		"DATABASE_URL": "/shared/database-url",
+		"TOKEN":        "/myapp/prod/legacy-token",
+	}
+
+	expanded, _, err := expandPrefix(context.Background(), provider, paramMap, "")
+	if err != nil {
+		t.Fatalf("expandPrefix() error = %v", err)
+	}
+
+	expected := ParameterMap{
+		"DB_PASSWORD":  "/myapp/prod/db-password",
+		"API_KEY":      "/myapp/prod/api-key",
+		"DATABASE_URL": "/shared/database-url",
+		"TOKEN":        "/myapp/prod/legacy-token",
+	}
+	if len(expanded) != len(expected) {
+		t.Errorf("Expected %d entries, got %d: %v", len(expected), len(expanded), expanded)
+	}
+	for key, want := range expected {
+		if expanded[key] != want {
+			t.Errorf("For key %s, expected %q, got %q", key, want, expanded[key])
+		}
+	}
+}
+
+func TestExpandPrefixDetectsCollisions(t *testing.T) {
+	provider := newMemProvider(map[string]string{
+		"/myapp/prod/db-password": "a",
+		"/myapp/prod/db_password": "b",
+	})
+
+	_, _, err := expandPrefix(context.Background(), provider, ParameterMap{}, "/myapp/prod/")
+	if err == nil {
+		t.Error("Expected error for colliding derived names, got nil")
+	}
+}
+
+func TestValidateParameterMapPrefixEntry(t *testing.T) {
+	if err := validateParameterMap(ParameterMap{prefixMapKey: "/myapp/prod/"}); err != nil {
+		t.Errorf("Expected SSM prefix entry to be valid, got %v", err)
+	}
+	if err := validateParameterMap(ParameterMap{prefixMapKey: "myapp/prod/"}); err == nil {
+		t.Error("Expected error for SSM prefix without leading slash, got nil")
+	}
+	if err := validateAzureParameterMap(ParameterMap{prefixMapKey: "myapp-prod-"}); err != nil {
+		t.Errorf("Expected Azure prefix entry to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// errSecretNotFound is returned by a SecretProvider when the requested secret does not exist
+var errSecretNotFound = errors.New("secret not found")
+
+// authError marks a failure to authenticate with, or be authorized by, a secret provider
+type authError struct {
+	err error
+}
+
+func (e *authError) Error() string {
+	return e.err.Error()
+}
+
+func (e *authError) Unwrap() error {
+	return e.err
+}
+
+// isAuthError reports whether err was caused by an authentication or authorization failure
+func isAuthError(err error) bool {
+	var authErr *authError
+	return errors.As(err, &authErr)
+}
+
+// SecretProvider is a secret backend that EnvChanter can pull from, push to and sync with.
+// Paths are backend specific: SSM parameter paths or Secrets Manager secrets for AWS, secret
+// names for Azure Key Vault and secret paths with a field for HashiCorp Vault.
+type SecretProvider interface {
+	// Name returns a short display name for the backend, used in output
+	Name() string
+
+	// ValidateMap validates a parameter map against the backend's naming rules
+	ValidateMap(paramMap ParameterMap) error
+
+	// Get returns the current value stored at path, or errSecretNotFound
+	Get(ctx context.Context, path string) (string, error)
+
+	// Put creates or overwrites the value stored at path
+	Put(ctx context.Context, path, value string) error
+
+	// List returns the paths of all secrets whose path starts with prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Delete removes the value stored at path, or returns errSecretNotFound
+	Delete(ctx context.Context, path string) error
+}
+
+// batchGetter is implemented by providers that can fetch several paths in one request
+type batchGetter interface {
+	// BatchSize returns the maximum number of paths accepted by GetBatch
+	BatchSize() int
+
+	// GetBatch returns the values of the given paths; paths that do not exist are omitted
+	GetBatch(ctx context.Context, paths []string) (map[string]string, error)
+}
+
+// valueLister is implemented by providers whose listing returns the values of the secrets too,
+// so a prefix pull does not have to fetch them again
+type valueLister interface {
+	// ListValues returns the paths of all secrets whose path starts with prefix, and the values
+	// of those the listing returned. Paths without a value are fetched as usual.
+	ListValues(ctx context.Context, prefix string) ([]string, map[string]string, error)
+}
+
+// batchPutter is implemented by providers that keep several paths in one secret, so that a push
+// can write each secret once instead of creating a new version of it for every path
+type batchPutter interface {
+	// PutBatch creates or overwrites the values of the given paths
+	PutBatch(ctx context.Context, values map[string]string) error
+}
+
+// mapUser is implemented by providers whose behavior or name depends on the paths in the
+// resolved parameter map
+type mapUser interface {
+	// UseMap is called once with the resolved map, before any path in it is read or written
+	UseMap(paramMap ParameterMap)
+}
+
+// useParameterMap passes the resolved map to providers that implement mapUser
+func useParameterMap(provider SecretProvider, paramMap ParameterMap) {
+	if user, ok := provider.(mapUser); ok {
+		user.UseMap(paramMap)
+	}
+}
+
+// providerOptions holds the command-line settings that select and configure the secret backend
+type providerOptions struct {
+	// Azure selects Azure Key Vault, in the vault named VaultName
+	Azure     bool
+	VaultName string
+
+	// Vault selects HashiCorp Vault, configured by VaultConfig
+	Vault       bool
+	VaultConfig vaultConfig
+
+	// SecretsManager selects AWS Secrets Manager for every entry instead of SSM
+	SecretsManager bool
+
+	// Profile and Region configure the AWS backends
+	Profile string
+	Region  string
+}
+
+// newProvider creates the secret backend selected on the command line. AWS maps are served from
+// SSM, with sm: entries from Secrets Manager, unless SecretsManager selects Secrets Manager for
+// every entry.
+func newProvider(ctx context.Context, opts providerOptions) (SecretProvider, error) {
+	if opts.Azure {
+		client, err := createAzureClient(ctx, opts.VaultName)
+		if err != nil {
+			return nil, err
+		}
+		return newAzureProvider(client), nil
+	}
+
+	if opts.Vault {
+		provider, err := newVaultProvider(opts.VaultConfig, nil)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	}
+
+	cfg, err := loadAWSConfig(ctx, opts.Profile, opts.Region)
+	if err != nil {
+		return nil, err
+	}
+	return newAWSProvider(cfg, opts.SecretsManager), nil
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/aws/smithy-go"
+)
+
+// ssmAPI is the subset of the SSM client used by ssmProvider
+type ssmAPI interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+	GetParameters(ctx context.Context, params *ssm.GetParametersInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersOutput, error)
+	PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error)
+	DeleteParameter(ctx context.Context, params *ssm.DeleteParameterInput, optFns ...func(*ssm.Options)) (*ssm.DeleteParameterOutput, error)
+	GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error)
+}
+
+// awsAuthErrorCodes are the AWS error codes that indicate missing, invalid or insufficient credentials
+var awsAuthErrorCodes = map[string]bool{
+	"AccessDenied":                true,
+	"AccessDeniedException":       true,
+	"ExpiredTokenException":       true,
+	"InvalidClientTokenId":        true,
+	"InvalidSignatureException":   true,
+	"MissingAuthenticationToken":  true,
+	"UnrecognizedClientException": true,
+}
+
+// translateSSMError maps SSM errors onto auth errors and errSecretNotFound
+func translateSSMError(err error) error {
+	var notFound *types.ParameterNotFound
+	if errors.As(err, &notFound) {
+		return errSecretNotFound
+	}
+
+	if authErr := checkAWSAuthError(err, "SSM"); authErr != nil {
+		return authErr
+	}
+
+	return err
+}
+
+// checkAWSAuthError returns an auth error if err was caused by missing or insufficient AWS
+// credentials for the named service, or nil otherwise
+func checkAWSAuthError(err error, service string) error {
+	// Credentials could not be loaded or refreshed
+	var signingErr *v4.SigningError
+	if errors.As(err, &signingErr) {
+		return &authError{fmt.Errorf("AWS authentication failed: no valid credentials available. Please run 'aws configure' or check --profile: %w", err)}
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && awsAuthErrorCodes[apiErr.ErrorCode()] {
+		return &authError{fmt.Errorf("AWS authorization failed (%s): check the IAM permissions for %s", apiErr.ErrorCode(), service)}
+	}
+
+	return nil
+}
+
+// ssmMaxBatchSize is the maximum number of names accepted by a single GetParameters call
+const ssmMaxBatchSize = 10
+
+// ssmProvider is a SecretProvider backed by AWS SSM Parameter Store
+type ssmProvider struct {
+	client ssmAPI
+}
+
+// newSSMProvider creates an SSM provider from an AWS config
+func newSSMProvider(cfg aws.Config) *ssmProvider {
+	return &ssmProvider{client: ssm.NewFromConfig(cfg)}
+}
+
+// loadAWSConfig creates an AWS config with optional profile and region
+func loadAWSConfig(ctx context.Context, profile, region string) (aws.Config, error) {
+	var opts []func(*config.LoadOptions) error
+
+	if profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(profile))
+	}
+
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func (p *ssmProvider) Name() string {
+	return "SSM"
+}
+
+func (p *ssmProvider) ValidateMap(paramMap ParameterMap) error {
+	return validateParameterMap(paramMap)
+}
+
+func (p *ssmProvider) Get(ctx context.Context, path string) (string, error) {
+	input := &ssm.GetParameterInput{
+		Name:           &path,
+		WithDecryption: boolPtr(true),
+	}
+
+	result, err := p.client.GetParameter(ctx, input)
+	if err != nil {
+		return "", translateSSMError(err)
+	}
+
+	if result.Parameter == nil || result.Parameter.Value == nil {
+		return "", errSecretNotFound
+	}
+
+	return *result.Parameter.Value, nil
+}
+
+func (p *ssmProvider) BatchSize() int {
+	return ssmMaxBatchSize
+}
+
+// GetBatch fetches up to ssmMaxBatchSize parameters with a single GetParameters call.
+// Names reported in InvalidParameters are missing and omitted from the result; a name that is
+// neither returned nor reported invalid is an error.
+func (p *ssmProvider) GetBatch(ctx context.Context, paths []string) (map[string]string, error) {
+	values := make(map[string]string, len(paths))
+
+	// GetParameters rejects duplicate names, so request each path once
+	seen := make(map[string]bool, len(paths))
+	names := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			names = append(names, path)
+		}
+	}
+
+	for start := 0; start < len(names); start += ssmMaxBatchSize {
+		input := &ssm.GetParametersInput{
+			Names:          names[start:min(start+ssmMaxBatchSize, len(names))],
+			WithDecryption: boolPtr(true),
+		}
+
+		result, err := p.client.GetParameters(ctx, input)
+		if err != nil {
+			return nil, translateSSMError(err)
+		}
+
+		for _, param := range result.Parameters {
+			if param.Name != nil && param.Value != nil {
+				values[*param.Name] = *param.Value
+			}
+		}
+
+		invalid := make(map[string]bool, len(result.InvalidParameters))
+		for _, name := range result.InvalidParameters {
+			invalid[name] = true
+		}
+		for _, name := range input.Names {
+			if _, found := values[name]; !found && !invalid[name] {
+				return nil, fmt.Errorf("GetParameters returned neither a value nor an InvalidParameters entry for a requested parameter")
+			}
+		}
+	}
+
+	return values, nil
+}
+
+func (p *ssmProvider) Put(ctx context.Context, path, value string) error {
+	input := &ssm.PutParameterInput{
+		Name:      &path,
+		Value:     &value,
+		Type:      types.ParameterTypeSecureString,
+		Overwrite: boolPtr(true),
+	}
+
+	_, err := p.client.PutParameter(ctx, input)
+	if err != nil {
+		return translateSSMError(err)
+	}
+	return nil
+}
+
+// List returns every parameter in the hierarchy under prefix, following pagination
+func (p *ssmProvider) List(ctx context.Context, prefix string) ([]string, error) {
+	paths, _, err := p.ListValues(ctx, prefix)
+	return paths, err
+}
+
+// ListValues lists the parameters under prefix together with their decrypted values, which
+// GetParametersByPath returns anyway, following pagination
+func (p *ssmProvider) ListValues(ctx context.Context, prefix string) ([]string, map[string]string, error) {
+	// GetParametersByPath expects the hierarchy without a trailing slash
+	path := strings.TrimSuffix(prefix, "/")
+	if path == "" {
+		path = "/"
+	}
+
+	input := &ssm.GetParametersByPathInput{
+		Path:           &path,
+		Recursive:      boolPtr(true),
+		WithDecryption: boolPtr(true),
+	}
+
+	var paths []string
+	values := make(map[string]string)
+	paginator := ssm.NewGetParametersByPathPaginator(p.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, translateSSMError(err)
+		}
+		for _, param := range page.Parameters {
+			if param.Name == nil {
+				continue
+			}
+			paths = append(paths, *param.Name)
+			if param.Value != nil {
+				values[*param.Name] = *param.Value
+			}
+		}
+	}
+
+	return paths, values, nil
+}
+
+func (p *ssmProvider) Delete(ctx context.Context, path string) error {
+	_, err := p.client.DeleteParameter(ctx, &ssm.DeleteParameterInput{Name: &path})
+	if err != nil {
+		return translateSSMError(err)
+	}
+	return nil
+}
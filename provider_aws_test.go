@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/aws/smithy-go"
+)
+
+// fakeSSM is an in-memory ssmAPI used by tests
+type fakeSSM struct {
+	mu                 sync.Mutex
+	values             map[string]string
+	getParametersCalls [][]string
+	getParameterCalls  int
+	putParameterCalls  int
+
+	// unanswered names are left out of both Parameters and InvalidParameters
+	unanswered map[string]bool
+}
+
+func newFakeSSM(values map[string]string) *fakeSSM {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	return &fakeSSM{values: values}
+}
+
+func (f *fakeSSM) GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.getParameterCalls++
+	value, ok := f.values[*params.Name]
+	if !ok {
+		return nil, &types.ParameterNotFound{}
+	}
+	return &ssm.GetParameterOutput{Parameter: &types.Parameter{Name: params.Name, Value: &value}}, nil
+}
+
+func (f *fakeSSM) GetParameters(ctx context.Context, params *ssm.GetParametersInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(params.Names) > ssmMaxBatchSize {
+		return nil, fmt.Errorf("too many names: %d", len(params.Names))
+	}
+	f.getParametersCalls = append(f.getParametersCalls, params.Names)
+
+	out := &ssm.GetParametersOutput{}
+	for _, name := range params.Names {
+		if f.unanswered[name] {
+			continue
+		}
+		value, ok := f.values[name]
+		if !ok {
+			out.InvalidParameters = append(out.InvalidParameters, name)
+			continue
+		}
+		out.Parameters = append(out.Parameters, types.Parameter{Name: &name, Value: &value})
+	}
+	return out, nil
+}
+
+func (f *fakeSSM) PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.putParameterCalls++
+	f.values[*params.Name] = *params.Value
+	return &ssm.PutParameterOutput{}, nil
+}
+
+func (f *fakeSSM) DeleteParameter(ctx context.Context, params *ssm.DeleteParameterInput, optFns ...func(*ssm.Options)) (*ssm.DeleteParameterOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.values[*params.Name]; !ok {
+		return nil, &types.ParameterNotFound{}
+	}
+	delete(f.values, *params.Name)
+	return &ssm.DeleteParameterOutput{}, nil
+}
+
+// GetParametersByPath returns matching parameters two per page to exercise pagination
+func (f *fakeSSM) GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	prefix := strings.TrimSuffix(*params.Path, "/") + "/"
+	var names []string
+	for name := range f.values {
+		rel, ok := strings.CutPrefix(name, prefix)
+		if !ok || (!aws.ToBool(params.Recursive) && strings.Contains(rel, "/")) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	start := 0
+	if params.NextToken != nil {
+		start, _ = strconv.Atoi(*params.NextToken)
+	}
+	end := min(start+2, len(names))
+
+	out := &ssm.GetParametersByPathOutput{}
+	for _, name := range names[start:end] {
+		value := f.values[name]
+		out.Parameters = append(out.Parameters, types.Parameter{Name: aws.String(name), Value: &value})
+	}
+	if end < len(names) {
+		out.NextToken = aws.String(strconv.Itoa(end))
+	}
+	return out, nil
+}
+
+func TestSSMProviderFetchUsesBatches(t *testing.T) {
+	values := make(map[string]string)
+	paramMap := make(ParameterMap)
+	for i := 0; i < 25; i++ {
+		path := fmt.Sprintf("/myapp/dev/key-%02d", i)
+		paramMap[fmt.Sprintf("KEY_%02d", i)] = path
+		if i != 7 && i != 19 {
+			values[path] = fmt.Sprintf("value-%02d", i)
+		}
+	}
+	client := newFakeSSM(values)
+	provider := &ssmProvider{client: client}
+
+	envVars, err := fetchParameters(context.Background(), provider, paramMap, 2)
+	if err != nil {
+		t.Fatalf("fetchParameters() error = %v", err)
+	}
+
+	if len(client.getParametersCalls) != 3 {
+		t.Errorf("Expected 3 GetParameters calls, got %d", len(client.getParametersCalls))
+	}
+	if client.getParameterCalls != 0 {
+		t.Errorf("Expected no GetParameter calls, got %d", client.getParameterCalls)
+	}
+
+	if len(envVars) != 23 {
+		t.Errorf("Expected 23 parameters, got %d", len(envVars))
+	}
+	for _, missing := range []string{"KEY_07", "KEY_19"} {
+		if _, ok := envVars[missing]; ok {
+			t.Errorf("Expected %s to be reported missing", missing)
+		}
+	}
+	if envVars["KEY_24"] != "value-24" {
+		t.Errorf("Expected KEY_24 to be 'value-24', got %q", envVars["KEY_24"])
+	}
+}
+
+func TestSSMProviderGetBatchDeduplicatesNames(t *testing.T) {
+	client := newFakeSSM(map[string]string{"/myapp/dev/shared": "value"})
+	provider := &ssmProvider{client: client}
+
+	values, err := provider.GetBatch(context.Background(), []string{"/myapp/dev/shared", "/myapp/dev/shared"})
+	if err != nil {
+		t.Fatalf("GetBatch() error = %v", err)
+	}
+
+	if len(client.getParametersCalls) != 1 || len(client.getParametersCalls[0]) != 1 {
+		t.Errorf("Expected a single GetParameters call with one name, got %v", client.getParametersCalls)
+	}
+	if values["/myapp/dev/shared"] != "value" {
+		t.Errorf("Expected 'value', got %q", values["/myapp/dev/shared"])
+	}
+}
+
+func TestSSMProviderGetBatchUsesInvalidParameters(t *testing.T) {
+	client := newFakeSSM(map[string]string{"/myapp/dev/api-key": "value"})
+	provider := &ssmProvider{client: client}
+	ctx := context.Background()
+
+	values, err := provider.GetBatch(ctx, []string{"/myapp/dev/api-key", "/myapp/dev/missing"})
+	if err != nil {
+		t.Fatalf("GetBatch() error = %v", err)
+	}
+	if len(values) != 1 || values["/myapp/dev/api-key"] != "value" {
+		t.Errorf("GetBatch() = %v, want only /myapp/dev/api-key", values)
+	}
+
+	// A name missing from both lists is not silently treated as not found
+	client.unanswered = map[string]bool{"/myapp/dev/missing": true}
+	if _, err := provider.GetBatch(ctx, []string{"/myapp/dev/api-key", "/myapp/dev/missing"}); err == nil {
+		t.Error("Expected an error for a name missing from both Parameters and InvalidParameters")
+	}
+}
+
+func TestSSMProviderFetchBatchErrorDoesNotBlameOneKey(t *testing.T) {
+	client := newFakeSSM(map[string]string{"/myapp/dev/api-key": "value"})
+	client.unanswered = map[string]bool{"/myapp/dev/missing": true}
+	provider := &ssmProvider{client: client}
+	paramMap := ParameterMap{"API_KEY": "/myapp/dev/api-key", "MISSING": "/myapp/dev/missing"}
+
+	_, err := fetchParameters(context.Background(), provider, paramMap, 1)
+	if err == nil {
+		t.Fatal("Expected error from fetchParameters, got nil")
+	}
+	if !strings.Contains(err.Error(), "failed to get a batch of parameters (API_KEY, MISSING)") {
+		t.Errorf("Error should describe the whole batch: %v", err)
+	}
+}
+
+func TestSSMProviderGetNotFound(t *testing.T) {
+	provider := &ssmProvider{client: newFakeSSM(nil)}
+
+	_, err := provider.Get(context.Background(), "/myapp/dev/missing")
+	if err != errSecretNotFound {
+		t.Errorf("Expected errSecretNotFound, got %v", err)
+	}
+}
+
+func TestSSMProviderListFollowsPagination(t *testing.T) {
+	provider := &ssmProvider{client: newFakeSSM(map[string]string{
+		"/myapp/prod/db-password":  "a",
+		"/myapp/prod/api-key":      "b",
+		"/myapp/prod/database/url": "c",
+		"/myapp/prod/cache-url":    "d",
+		"/myapp/test/db-password":  "e",
+	})}
+
+	paths, err := provider.List(context.Background(), "/myapp/prod/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	expected := []string{"/myapp/prod/api-key", "/myapp/prod/cache-url", "/myapp/prod/database/url", "/myapp/prod/db-password"}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+}
+
+func TestSSMPrefixPullUsesListedValues(t *testing.T) {
+	client := newFakeSSM(map[string]string{
+		"/myapp/prod/db-password": "a",
+		"/myapp/prod/api-key":     "b",
+		"/myapp/prod/cache-url":   "c",
+		"/shared/database-url":    "d",
+	})
+	mapFile := filepath.Join(t.TempDir(), "map.json")
+	if err := os.WriteFile(mapFile, []byte(`{"*": "/myapp/prod/", "DATABASE_URL": "/shared/database-url"}`), 0644); err != nil {
+		t.Fatalf("Failed to create map file: %v", err)
+	}
+
+	ctx := context.Background()
+	paramMap, _, provider, err := resolveParameterMap(ctx, &ssmProvider{client: client}, mapFile, "", nil)
+	if err != nil {
+		t.Fatalf("resolveParameterMap() error = %v", err)
+	}
+	envVars, err := fetchParameters(ctx, provider, paramMap, 2)
+	if err != nil {
+		t.Fatalf("fetchParameters() error = %v", err)
+	}
+
+	expected := map[string]string{"DB_PASSWORD": "a", "API_KEY": "b", "CACHE_URL": "c", "DATABASE_URL": "d"}
+	for key, want := range expected {
+		if envVars[key] != want {
+			t.Errorf("%s = %q, want %q", key, envVars[key], want)
+		}
+	}
+
+	// Only the explicit entry outside the prefix is fetched after listing
+	if len(client.getParametersCalls) != 1 || strings.Join(client.getParametersCalls[0], ",") != "/shared/database-url" {
+		t.Errorf("Expected one GetParameters call for /shared/database-url, got %v", client.getParametersCalls)
+	}
+}
+
+func TestTranslateSSMError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantNotFound bool
+		wantAuth     bool
+	}{
+		{"Parameter not found", &types.ParameterNotFound{}, true, false},
+		{"Access denied", &smithy.GenericAPIError{Code: "AccessDeniedException"}, false, true},
+		{"Expired token", &smithy.GenericAPIError{Code: "ExpiredTokenException"}, false, true},
+		{"Missing credentials", &v4.SigningError{Err: errors.New("no credentials")}, false, true},
+		{"Throttled", &smithy.GenericAPIError{Code: "ThrottlingException"}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateSSMError(tt.err)
+			if errors.Is(err, errSecretNotFound) != tt.wantNotFound {
+				t.Errorf("translateSSMError() not found = %v, want %v", errors.Is(err, errSecretNotFound), tt.wantNotFound)
+			}
+			if isAuthError(err) != tt.wantAuth {
+				t.Errorf("translateSSMError() auth = %v, want %v", isAuthError(err), tt.wantAuth)
+			}
+		})
+	}
+}
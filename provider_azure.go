@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+// azureSecretsAPI is the subset of the Key Vault secrets client used by azureProvider
+type azureSecretsAPI interface {
+	GetSecret(ctx context.Context, name string, version string, options *azsecrets.GetSecretOptions) (azsecrets.GetSecretResponse, error)
+	SetSecret(ctx context.Context, name string, parameters azsecrets.SetSecretParameters, options *azsecrets.SetSecretOptions) (azsecrets.SetSecretResponse, error)
+	DeleteSecret(ctx context.Context, name string, options *azsecrets.DeleteSecretOptions) (azsecrets.DeleteSecretResponse, error)
+	NewListSecretPropertiesPager(options *azsecrets.ListSecretPropertiesOptions) *runtime.Pager[azsecrets.ListSecretPropertiesResponse]
+}
+
+// azureProvider is a SecretProvider backed by Azure Key Vault
+type azureProvider struct {
+	client azureSecretsAPI
+}
+
+// newAzureProvider creates an Azure Key Vault provider from a secrets client
+func newAzureProvider(client azureSecretsAPI) *azureProvider {
+	return &azureProvider{client: client}
+}
+
+// createAzureClient creates an Azure Key Vault client
+func createAzureClient(ctx context.Context, vaultName string) (*azsecrets.Client, error) {
+	// Create default Azure credential (uses managed identity, environment variables, or Azure CLI)
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure credential: %w", err)
+	}
+
+	// Construct vault URL
+	vaultURL := fmt.Sprintf("https://%s.vault.azure.net/", vaultName)
+
+	// Create secrets client
+	client, err := azsecrets.NewClient(vaultURL, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Key Vault client: %w", err)
+	}
+
+	return client, nil
+}
+
+// checkAzureAuthError checks if an error is an authentication or authorization error
+func checkAzureAuthError(err error) error {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.StatusCode {
+		case http.StatusUnauthorized: // 401
+			return &authError{fmt.Errorf("Azure authentication failed: no valid credentials available. Please run 'az login' or configure Azure credentials")}
+		case http.StatusForbidden: // 403
+			return &authError{fmt.Errorf("Azure authorization failed: insufficient permissions to access Key Vault. Ensure you have the required role assigned (e.g., 'Key Vault Secrets User' for read, 'Key Vault Secrets Officer' for write)")}
+		}
+	}
+	return nil
+}
+
+// translateAzureError maps Key Vault errors onto auth errors and errSecretNotFound
+func translateAzureError(err error) error {
+	// Check for authentication/authorization errors first
+	if authErr := checkAzureAuthError(err); authErr != nil {
+		return authErr
+	}
+
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+		return errSecretNotFound
+	}
+
+	return err
+}
+
+func (p *azureProvider) Name() string {
+	return "Azure"
+}
+
+func (p *azureProvider) ValidateMap(paramMap ParameterMap) error {
+	return validateAzureParameterMap(paramMap)
+}
+
+func (p *azureProvider) Get(ctx context.Context, path string) (string, error) {
+	// Get the latest version of the secret (empty version string gets latest)
+	resp, err := p.client.GetSecret(ctx, path, "", nil)
+	if err != nil {
+		return "", translateAzureError(err)
+	}
+
+	if resp.Value == nil {
+		return "", errSecretNotFound
+	}
+
+	return *resp.Value, nil
+}
+
+func (p *azureProvider) Put(ctx context.Context, path, value string) error {
+	params := azsecrets.SetSecretParameters{
+		Value: &value,
+	}
+
+	_, err := p.client.SetSecret(ctx, path, params, nil)
+	if err != nil {
+		return translateAzureError(err)
+	}
+	return nil
+}
+
+func (p *azureProvider) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	pager := p.client.NewListSecretPropertiesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, translateAzureError(err)
+		}
+		for _, props := range page.Value {
+			if props.ID == nil {
+				continue
+			}
+			// Disabled secrets cannot be read, so a pull would fail on them
+			if props.Attributes != nil && props.Attributes.Enabled != nil && !*props.Attributes.Enabled {
+				continue
+			}
+			if name := props.ID.Name(); strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names, nil
+}
+
+func (p *azureProvider) Delete(ctx context.Context, path string) error {
+	_, err := p.client.DeleteSecret(ctx, path, nil)
+	if err != nil {
+		return translateAzureError(err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+// fakeAzureSecrets is an in-memory azureSecretsAPI used by tests. Like Key Vault, it refuses to
+// read a disabled secret with 403 Forbidden.
+type fakeAzureSecrets struct {
+	values   map[string]string
+	disabled map[string]bool
+}
+
+func (f *fakeAzureSecrets) GetSecret(ctx context.Context, name string, version string, options *azsecrets.GetSecretOptions) (azsecrets.GetSecretResponse, error) {
+	if f.disabled[name] {
+		return azsecrets.GetSecretResponse{}, &azcore.ResponseError{StatusCode: http.StatusForbidden}
+	}
+	value, ok := f.values[name]
+	if !ok {
+		return azsecrets.GetSecretResponse{}, &azcore.ResponseError{StatusCode: http.StatusNotFound}
+	}
+	return azsecrets.GetSecretResponse{Secret: azsecrets.Secret{Value: &value}}, nil
+}
+
+func (f *fakeAzureSecrets) SetSecret(ctx context.Context, name string, parameters azsecrets.SetSecretParameters, options *azsecrets.SetSecretOptions) (azsecrets.SetSecretResponse, error) {
+	f.values[name] = *parameters.Value
+	return azsecrets.SetSecretResponse{}, nil
+}
+
+func (f *fakeAzureSecrets) DeleteSecret(ctx context.Context, name string, options *azsecrets.DeleteSecretOptions) (azsecrets.DeleteSecretResponse, error) {
+	delete(f.values, name)
+	return azsecrets.DeleteSecretResponse{}, nil
+}
+
+func (f *fakeAzureSecrets) NewListSecretPropertiesPager(options *azsecrets.ListSecretPropertiesOptions) *runtime.Pager[azsecrets.ListSecretPropertiesResponse] {
+	var props []*azsecrets.SecretProperties
+	for name := range f.values {
+		id := azsecrets.ID("https://myvault.vault.azure.net/secrets/" + name)
+		enabled := !f.disabled[name]
+		props = append(props, &azsecrets.SecretProperties{ID: &id, Attributes: &azsecrets.SecretAttributes{Enabled: &enabled}})
+	}
+
+	return runtime.NewPager(runtime.PagingHandler[azsecrets.ListSecretPropertiesResponse]{
+		More: func(page azsecrets.ListSecretPropertiesResponse) bool {
+			return false
+		},
+		Fetcher: func(ctx context.Context, page *azsecrets.ListSecretPropertiesResponse) (azsecrets.ListSecretPropertiesResponse, error) {
+			return azsecrets.ListSecretPropertiesResponse{SecretPropertiesListResult: azsecrets.SecretPropertiesListResult{Value: props}}, nil
+		},
+	})
+}
+
+func TestAzureProviderListSkipsDisabledSecrets(t *testing.T) {
+	client := &fakeAzureSecrets{
+		values: map[string]string{
+			"myapp-prod-db-password": "secret123",
+			"myapp-prod-api-key":     "my-api-key",
+			"myapp-prod-old-token":   "retired",
+			"other-app-key":          "other",
+		},
+		disabled: map[string]bool{"myapp-prod-old-token": true},
+	}
+	provider := newAzureProvider(client)
+	ctx := context.Background()
+
+	names, err := provider.List(ctx, "myapp-prod-")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	sort.Strings(names)
+	want := []string{"myapp-prod-api-key", "myapp-prod-db-password"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("List() = %v, want %v", names, want)
+	}
+
+	// A disabled secret under the prefix must not fail the pull with an auth error
+	paramMap, _, listed, err := resolveParameterMap(ctx, provider, "", "myapp-prod-", nil)
+	if err != nil {
+		t.Fatalf("resolveParameterMap() error = %v", err)
+	}
+	envVars, err := fetchParameters(ctx, listed, paramMap, 1)
+	if err != nil {
+		t.Fatalf("fetchParameters() error = %v", err)
+	}
+	if len(envVars) != 2 {
+		t.Errorf("fetchParameters() = %v, want 2 values", envVars)
+	}
+}
@@ -0,0 +1,560 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// secretsManagerAPI is the subset of the Secrets Manager client used by secretsManagerProvider
+type secretsManagerAPI interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+	PutSecretValue(ctx context.Context, params *secretsmanager.PutSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.PutSecretValueOutput, error)
+	CreateSecret(ctx context.Context, params *secretsmanager.CreateSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.CreateSecretOutput, error)
+	DeleteSecret(ctx context.Context, params *secretsmanager.DeleteSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.DeleteSecretOutput, error)
+	ListSecrets(ctx context.Context, params *secretsmanager.ListSecretsInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.ListSecretsOutput, error)
+}
+
+// secretRef is a parsed Secrets Manager path of the form name[?stage=VERSION_STAGE][#json-key]
+type secretRef struct {
+	// ID is the secret name or ARN
+	ID string
+	// Stage is the version stage to read, or empty for AWSCURRENT
+	Stage string
+	// Key selects a field of a secret that stores a JSON object, or is empty for the whole secret
+	Key string
+}
+
+// parseSecretRef splits a Secrets Manager path into the secret, version stage and JSON key.
+// Neither ? nor # can occur in secret names or ARNs.
+func parseSecretRef(path string) (secretRef, error) {
+	rest, key, hasKey := strings.Cut(path, "#")
+	if hasKey && key == "" {
+		return secretRef{}, fmt.Errorf("empty JSON key after #")
+	}
+
+	id, query, hasQuery := strings.Cut(rest, "?")
+	ref := secretRef{ID: id, Key: key}
+	if hasQuery {
+		stage, ok := strings.CutPrefix(query, "stage=")
+		if !ok || stage == "" {
+			return secretRef{}, fmt.Errorf("expected ?stage=VERSION_STAGE after the secret name")
+		}
+		ref.Stage = stage
+	}
+
+	return ref, nil
+}
+
+// translateSecretsManagerError maps Secrets Manager errors onto auth errors and errSecretNotFound
+func translateSecretsManagerError(err error) error {
+	var notFound *smtypes.ResourceNotFoundException
+	if errors.As(err, &notFound) {
+		return errSecretNotFound
+	}
+
+	if authErr := checkAWSAuthError(err, "Secrets Manager"); authErr != nil {
+		return authErr
+	}
+
+	return err
+}
+
+// secretsManagerBatchSize is the number of paths fetched together, so that paths reading
+// different keys of the same secret share one GetSecretValue call
+const secretsManagerBatchSize = 10
+
+// secretsManagerProvider is a SecretProvider backed by AWS Secrets Manager. Paths name a secret,
+// optionally with a version stage and a key to read from a secret that stores a JSON object.
+type secretsManagerProvider struct {
+	client secretsManagerAPI
+}
+
+// newSecretsManagerProvider creates a Secrets Manager provider from an AWS config
+func newSecretsManagerProvider(cfg aws.Config) *secretsManagerProvider {
+	return &secretsManagerProvider{client: secretsmanager.NewFromConfig(cfg)}
+}
+
+func (p *secretsManagerProvider) Name() string {
+	return "Secrets Manager"
+}
+
+func (p *secretsManagerProvider) ValidateMap(paramMap ParameterMap) error {
+	return validateSecretsManagerMap(paramMap)
+}
+
+// getSecretString returns the string value of a secret at the reference's version stage
+func (p *secretsManagerProvider) getSecretString(ctx context.Context, ref secretRef) (string, error) {
+	input := &secretsmanager.GetSecretValueInput{SecretId: &ref.ID}
+	if ref.Stage != "" {
+		input.VersionStage = &ref.Stage
+	}
+
+	result, err := p.client.GetSecretValue(ctx, input)
+	if err != nil {
+		return "", translateSecretsManagerError(err)
+	}
+
+	if result.SecretString == nil {
+		return "", fmt.Errorf("secret stores binary data, which is not supported")
+	}
+
+	return *result.SecretString, nil
+}
+
+func (p *secretsManagerProvider) Get(ctx context.Context, path string) (string, error) {
+	ref, err := parseSecretRef(path)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := p.getSecretString(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	if ref.Key == "" {
+		return secret, nil
+	}
+	return secretJSONValue(secret, ref.Key)
+}
+
+func (p *secretsManagerProvider) BatchSize() int {
+	return secretsManagerBatchSize
+}
+
+// GetBatch fetches each distinct secret and version stage once and extracts every requested
+// value from it. Secrets and JSON keys that do not exist are omitted from the result.
+func (p *secretsManagerProvider) GetBatch(ctx context.Context, paths []string) (map[string]string, error) {
+	values := make(map[string]string, len(paths))
+	secrets := make(map[secretRef]string)
+	missing := make(map[secretRef]bool)
+
+	for _, path := range paths {
+		ref, err := parseSecretRef(path)
+		if err != nil {
+			return nil, err
+		}
+
+		whole := secretRef{ID: ref.ID, Stage: ref.Stage}
+		if missing[whole] {
+			continue
+		}
+		secret, fetched := secrets[whole]
+		if !fetched {
+			secret, err = p.getSecretString(ctx, whole)
+			if errors.Is(err, errSecretNotFound) {
+				missing[whole] = true
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			secrets[whole] = secret
+		}
+
+		if ref.Key == "" {
+			values[path] = secret
+			continue
+		}
+		value, err := secretJSONValue(secret, ref.Key)
+		if errors.Is(err, errSecretNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[path] = value
+	}
+
+	return values, nil
+}
+
+// Put stores value as the whole secret, or as one key of a secret that stores a JSON object,
+// creating the secret if it does not exist. With a version stage the new version gets that
+// label instead of AWSCURRENT.
+func (p *secretsManagerProvider) Put(ctx context.Context, path, value string) error {
+	ref, err := parseSecretRef(path)
+	if err != nil {
+		return err
+	}
+
+	if ref.Key == "" {
+		return p.putSecretString(ctx, ref, value)
+	}
+	return p.putSecretKeys(ctx, ref, map[string]string{ref.Key: value})
+}
+
+// PutBatch stores several values, writing each secret once: whole secrets first, then all keys
+// of the same secret in a single read-modify-write, so a push creates one version per secret
+func (p *secretsManagerProvider) PutBatch(ctx context.Context, values map[string]string) error {
+	var secrets []secretRef
+	keysBySecret := make(map[secretRef]map[string]string)
+	for _, path := range sortedKeys(values) {
+		ref, err := parseSecretRef(path)
+		if err != nil {
+			return err
+		}
+
+		if ref.Key == "" {
+			if err := p.putSecretString(ctx, ref, values[path]); err != nil {
+				return fmt.Errorf("failed to put %s: %w", path, err)
+			}
+			continue
+		}
+
+		whole := secretRef{ID: ref.ID, Stage: ref.Stage}
+		if keysBySecret[whole] == nil {
+			keysBySecret[whole] = make(map[string]string)
+			secrets = append(secrets, whole)
+		}
+		keysBySecret[whole][ref.Key] = values[path]
+	}
+
+	for _, secret := range secrets {
+		if err := p.putSecretKeys(ctx, secret, keysBySecret[secret]); err != nil {
+			return fmt.Errorf("failed to put %s: %w", secret.ID, err)
+		}
+	}
+	return nil
+}
+
+// putSecretKeys sets keys of a secret that stores a JSON object, keeping its other keys, and
+// writes it as one new version. A secret that does not exist is created.
+func (p *secretsManagerProvider) putSecretKeys(ctx context.Context, ref secretRef, values map[string]string) error {
+	fields, err := p.getSecretFields(ctx, ref)
+	if errors.Is(err, errSecretNotFound) {
+		fields = make(map[string]json.RawMessage)
+	} else if err != nil {
+		return err
+	}
+
+	for key, value := range values {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode value: %w", err)
+		}
+		fields[key] = encoded
+	}
+
+	return p.putSecretFields(ctx, ref, fields)
+}
+
+// putSecretString stores a new version of a secret, creating the secret if it does not exist
+func (p *secretsManagerProvider) putSecretString(ctx context.Context, ref secretRef, secret string) error {
+	input := &secretsmanager.PutSecretValueInput{
+		SecretId:     &ref.ID,
+		SecretString: &secret,
+	}
+	if ref.Stage != "" {
+		input.VersionStages = []string{ref.Stage}
+	}
+
+	_, err := p.client.PutSecretValue(ctx, input)
+	err = translateSecretsManagerError(err)
+	if !errors.Is(err, errSecretNotFound) {
+		return err
+	}
+
+	// A new secret starts with a single AWSCURRENT version
+	if ref.Stage != "" {
+		return fmt.Errorf("secret does not exist; create it without a version stage first")
+	}
+	_, err = p.client.CreateSecret(ctx, &secretsmanager.CreateSecretInput{
+		Name:         &ref.ID,
+		SecretString: &secret,
+	})
+	if err != nil {
+		return translateSecretsManagerError(err)
+	}
+	return nil
+}
+
+// getSecretFields reads a secret that stores a JSON object, keeping the values as raw JSON
+func (p *secretsManagerProvider) getSecretFields(ctx context.Context, ref secretRef) (map[string]json.RawMessage, error) {
+	secret, err := p.getSecretString(ctx, secretRef{ID: ref.ID, Stage: ref.Stage})
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("secret does not store a JSON object, so its keys cannot be used")
+	}
+	return fields, nil
+}
+
+// putSecretFields stores a JSON object as a new version of a secret
+func (p *secretsManagerProvider) putSecretFields(ctx context.Context, ref secretRef, fields map[string]json.RawMessage) error {
+	secret, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode secret: %w", err)
+	}
+	return p.putSecretString(ctx, secretRef{ID: ref.ID, Stage: ref.Stage}, string(secret))
+}
+
+// List returns the names of all secrets whose name starts with prefix, following pagination
+func (p *secretsManagerProvider) List(ctx context.Context, prefix string) ([]string, error) {
+	input := &secretsmanager.ListSecretsInput{
+		Filters: []smtypes.Filter{{Key: smtypes.FilterNameStringTypeName, Values: []string{prefix}}},
+	}
+
+	var names []string
+	paginator := secretsmanager.NewListSecretsPaginator(p.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, translateSecretsManagerError(err)
+		}
+		for _, secret := range page.SecretList {
+			// The name filter is not case-sensitive, so check the prefix exactly
+			if secret.Name != nil && strings.HasPrefix(*secret.Name, prefix) {
+				names = append(names, *secret.Name)
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// Delete schedules a secret for deletion with the default recovery window, or removes one key
+// from a secret that stores a JSON object
+func (p *secretsManagerProvider) Delete(ctx context.Context, path string) error {
+	ref, err := parseSecretRef(path)
+	if err != nil {
+		return err
+	}
+
+	if ref.Key == "" {
+		_, err := p.client.DeleteSecret(ctx, &secretsmanager.DeleteSecretInput{SecretId: &ref.ID})
+		if err != nil {
+			return translateSecretsManagerError(err)
+		}
+		return nil
+	}
+
+	fields, err := p.getSecretFields(ctx, ref)
+	if err != nil {
+		return err
+	}
+	if _, exists := fields[ref.Key]; !exists {
+		return errSecretNotFound
+	}
+	delete(fields, ref.Key)
+
+	return p.putSecretFields(ctx, ref, fields)
+}
+
+// secretJSONValue returns one key of a secret that stores a JSON object. String values are
+// returned as is and any other value as JSON, e.g. 5432 or {"host":"db"}. A missing key is
+// reported as errSecretNotFound.
+func secretJSONValue(secret, key string) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil || fields == nil {
+		return "", fmt.Errorf("secret does not store a JSON object, so key %q cannot be read", key)
+	}
+
+	raw, exists := fields[key]
+	if !exists {
+		return "", errSecretNotFound
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	return string(raw), nil
+}
+
+// smPathPrefix marks a mapping entry that is read from Secrets Manager rather than SSM
+const smPathPrefix = "sm:"
+
+// awsProvider serves SSM parameters, and Secrets Manager secrets for paths that start with
+// smPathPrefix, so one map can mix both. With defaultSM every path is a Secrets Manager secret,
+// with or without the prefix.
+type awsProvider struct {
+	ssm *ssmProvider
+	sm  *secretsManagerProvider
+
+	defaultSM bool
+
+	// usesSM is set when the resolved map contains a Secrets Manager entry
+	usesSM bool
+}
+
+// newAWSProvider creates a provider for SSM and Secrets Manager from an AWS config. defaultSM
+// reads every path from Secrets Manager.
+func newAWSProvider(cfg aws.Config, defaultSM bool) *awsProvider {
+	return &awsProvider{ssm: newSSMProvider(cfg), sm: newSecretsManagerProvider(cfg), defaultSM: defaultSM}
+}
+
+// secretsManagerPath returns the Secrets Manager path for path and true, or false for an SSM path
+func (p *awsProvider) secretsManagerPath(path string) (string, bool) {
+	if smPath, ok := strings.CutPrefix(path, smPathPrefix); ok {
+		return smPath, true
+	}
+	return path, p.defaultSM
+}
+
+func (p *awsProvider) Name() string {
+	switch {
+	case p.defaultSM:
+		return p.sm.Name()
+	case p.usesSM:
+		return "SSM and Secrets Manager"
+	}
+	return p.ssm.Name()
+}
+
+// UseMap records whether the resolved map reads any path from Secrets Manager
+func (p *awsProvider) UseMap(paramMap ParameterMap) {
+	p.usesSM = false
+	for _, path := range paramMap {
+		if _, ok := p.secretsManagerPath(path); ok {
+			p.usesSM = true
+			break
+		}
+	}
+}
+
+// ValidateMap validates SSM entries as SSM paths and Secrets Manager entries as secret paths
+func (p *awsProvider) ValidateMap(paramMap ParameterMap) error {
+	ssmMap, smMap := make(ParameterMap), make(ParameterMap)
+	for envKey, path := range paramMap {
+		if smPath, ok := p.secretsManagerPath(path); ok {
+			smMap[envKey] = smPath
+		} else {
+			ssmMap[envKey] = path
+		}
+	}
+
+	if len(smMap) > 0 {
+		if err := p.sm.ValidateMap(smMap); err != nil {
+			return err
+		}
+		if len(ssmMap) == 0 {
+			return nil
+		}
+	}
+
+	return p.ssm.ValidateMap(ssmMap)
+}
+
+func (p *awsProvider) Get(ctx context.Context, path string) (string, error) {
+	if smPath, ok := p.secretsManagerPath(path); ok {
+		return p.sm.Get(ctx, smPath)
+	}
+	return p.ssm.Get(ctx, path)
+}
+
+func (p *awsProvider) BatchSize() int {
+	return ssmMaxBatchSize
+}
+
+// GetBatch splits the paths between SSM and Secrets Manager and fetches each part in a batch
+func (p *awsProvider) GetBatch(ctx context.Context, paths []string) (map[string]string, error) {
+	var ssmPaths, smPaths []string
+	smPathOf := make(map[string]string)
+	for _, path := range paths {
+		if smPath, ok := p.secretsManagerPath(path); ok {
+			smPaths = append(smPaths, smPath)
+			smPathOf[path] = smPath
+		} else {
+			ssmPaths = append(ssmPaths, path)
+		}
+	}
+
+	values := make(map[string]string, len(paths))
+	if len(ssmPaths) > 0 {
+		ssmValues, err := p.ssm.GetBatch(ctx, ssmPaths)
+		if err != nil {
+			return nil, err
+		}
+		for path, value := range ssmValues {
+			values[path] = value
+		}
+	}
+	if len(smPaths) > 0 {
+		smValues, err := p.sm.GetBatch(ctx, smPaths)
+		if err != nil {
+			return nil, err
+		}
+		for path, smPath := range smPathOf {
+			if value, found := smValues[smPath]; found {
+				values[path] = value
+			}
+		}
+	}
+
+	return values, nil
+}
+
+func (p *awsProvider) Put(ctx context.Context, path, value string) error {
+	if smPath, ok := p.secretsManagerPath(path); ok {
+		return p.sm.Put(ctx, smPath, value)
+	}
+	return p.ssm.Put(ctx, path, value)
+}
+
+// PutBatch puts SSM parameters one by one and hands the Secrets Manager values to its PutBatch,
+// so each secret is written once
+func (p *awsProvider) PutBatch(ctx context.Context, values map[string]string) error {
+	smValues := make(map[string]string)
+	for _, path := range sortedKeys(values) {
+		if smPath, ok := p.secretsManagerPath(path); ok {
+			smValues[smPath] = values[path]
+			continue
+		}
+		if err := p.ssm.Put(ctx, path, values[path]); err != nil {
+			return fmt.Errorf("failed to put %s: %w", path, err)
+		}
+	}
+
+	if len(smValues) == 0 {
+		return nil
+	}
+	return p.sm.PutBatch(ctx, smValues)
+}
+
+// List lists SSM parameters, or Secrets Manager secrets for a Secrets Manager prefix, returning
+// them in the same form as prefix so they can be fetched through this provider
+func (p *awsProvider) List(ctx context.Context, prefix string) ([]string, error) {
+	smPrefix, ok := p.secretsManagerPath(prefix)
+	if !ok {
+		return p.ssm.List(ctx, prefix)
+	}
+
+	names, err := p.sm.List(ctx, smPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if smPrefix != prefix {
+		for i, name := range names {
+			names[i] = smPathPrefix + name
+		}
+	}
+	return names, nil
+}
+
+// ListValues returns the values along with the paths for an SSM prefix. Secrets Manager
+// listings do not include values, so those secrets are fetched as usual.
+func (p *awsProvider) ListValues(ctx context.Context, prefix string) ([]string, map[string]string, error) {
+	if _, ok := p.secretsManagerPath(prefix); ok {
+		paths, err := p.List(ctx, prefix)
+		return paths, nil, err
+	}
+	return p.ssm.ListValues(ctx, prefix)
+}
+
+func (p *awsProvider) Delete(ctx context.Context, path string) error {
+	if smPath, ok := p.secretsManagerPath(path); ok {
+		return p.sm.Delete(ctx, smPath)
+	}
+	return p.ssm.Delete(ctx, path)
+}
@@ -0,0 +1,556 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/smithy-go"
+)
+
+// fakeSecretsManager is an in-memory secretsManagerAPI used by tests. Secret versions are kept
+// per version stage.
+type fakeSecretsManager struct {
+	mu                  sync.Mutex
+	secrets             map[string]map[string]string
+	binary              map[string]bool
+	getSecretValueCalls int
+	putSecretValueCalls int
+	createSecretCalls   int
+}
+
+func newFakeSecretsManager(secrets map[string]string) *fakeSecretsManager {
+	f := &fakeSecretsManager{secrets: make(map[string]map[string]string), binary: make(map[string]bool)}
+	for name, value := range secrets {
+		f.secrets[name] = map[string]string{"AWSCURRENT": value}
+	}
+	return f
+}
+
+func (f *fakeSecretsManager) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.getSecretValueCalls++
+
+	if f.binary[*params.SecretId] {
+		return &secretsmanager.GetSecretValueOutput{Name: params.SecretId, SecretBinary: []byte{0x01}}, nil
+	}
+
+	stage := aws.ToString(params.VersionStage)
+	if stage == "" {
+		stage = "AWSCURRENT"
+	}
+	value, ok := f.secrets[*params.SecretId][stage]
+	if !ok {
+		return nil, &smtypes.ResourceNotFoundException{}
+	}
+	return &secretsmanager.GetSecretValueOutput{Name: params.SecretId, SecretString: &value}, nil
+}
+
+func (f *fakeSecretsManager) PutSecretValue(ctx context.Context, params *secretsmanager.PutSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.PutSecretValueOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.putSecretValueCalls++
+
+	versions, ok := f.secrets[*params.SecretId]
+	if !ok {
+		return nil, &smtypes.ResourceNotFoundException{}
+	}
+	stage := "AWSCURRENT"
+	if len(params.VersionStages) > 0 {
+		stage = params.VersionStages[0]
+	}
+	if stage == "AWSCURRENT" {
+		versions["AWSPREVIOUS"] = versions["AWSCURRENT"]
+	}
+	versions[stage] = *params.SecretString
+	return &secretsmanager.PutSecretValueOutput{}, nil
+}
+
+func (f *fakeSecretsManager) CreateSecret(ctx context.Context, params *secretsmanager.CreateSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.CreateSecretOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.createSecretCalls++
+
+	if _, exists := f.secrets[*params.Name]; exists {
+		return nil, &smtypes.ResourceExistsException{}
+	}
+	f.secrets[*params.Name] = map[string]string{"AWSCURRENT": *params.SecretString}
+	return &secretsmanager.CreateSecretOutput{Name: params.Name}, nil
+}
+
+func (f *fakeSecretsManager) DeleteSecret(ctx context.Context, params *secretsmanager.DeleteSecretInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.DeleteSecretOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.secrets[*params.SecretId]; !exists {
+		return nil, &smtypes.ResourceNotFoundException{}
+	}
+	delete(f.secrets, *params.SecretId)
+	return &secretsmanager.DeleteSecretOutput{}, nil
+}
+
+// ListSecrets applies a case-insensitive name prefix filter, like Secrets Manager, and returns
+// two secrets per page to exercise pagination
+func (f *fakeSecretsManager) ListSecrets(ctx context.Context, params *secretsmanager.ListSecretsInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.ListSecretsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var names []string
+	for name := range f.secrets {
+		matches := true
+		for _, filter := range params.Filters {
+			if filter.Key == smtypes.FilterNameStringTypeName && !strings.HasPrefix(strings.ToLower(name), strings.ToLower(filter.Values[0])) {
+				matches = false
+			}
+		}
+		if matches {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	start := 0
+	if params.NextToken != nil {
+		start, _ = strconv.Atoi(*params.NextToken)
+	}
+	end := min(start+2, len(names))
+
+	out := &secretsmanager.ListSecretsOutput{}
+	for _, name := range names[start:end] {
+		out.SecretList = append(out.SecretList, smtypes.SecretListEntry{Name: aws.String(name)})
+	}
+	if end < len(names) {
+		out.NextToken = aws.String(strconv.Itoa(end))
+	}
+	return out, nil
+}
+
+func TestParseSecretRef(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    secretRef
+		wantErr bool
+	}{
+		{"myapp/prod/db", secretRef{ID: "myapp/prod/db"}, false},
+		{"myapp/prod/db#password", secretRef{ID: "myapp/prod/db", Key: "password"}, false},
+		{"myapp/prod/db?stage=AWSPREVIOUS", secretRef{ID: "myapp/prod/db", Stage: "AWSPREVIOUS"}, false},
+		{"myapp/prod/db?stage=AWSPENDING#password", secretRef{ID: "myapp/prod/db", Stage: "AWSPENDING", Key: "password"}, false},
+		{"arn:aws:secretsmanager:eu-west-2:123456789012:secret:db-AbCdEf#user", secretRef{ID: "arn:aws:secretsmanager:eu-west-2:123456789012:secret:db-AbCdEf", Key: "user"}, false},
+		{"myapp/prod/db#", secretRef{}, true},
+		{"myapp/prod/db?version=2", secretRef{}, true},
+		{"myapp/prod/db?stage=", secretRef{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := parseSecretRef(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSecretRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSecretRef() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSecretsManagerPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"Plain name", "myapp/prod/db-password", false},
+		{"Name with allowed symbols", "team+app=prod.db@eu_west-2", false},
+		{"JSON key", "myapp/prod/db#password", false},
+		{"Version stage", "myapp/prod/db?stage=AWSPREVIOUS", false},
+		{"ARN", "arn:aws:secretsmanager:eu-west-2:123456789012:secret:db-AbCdEf", false},
+		{"Empty", "", true},
+		{"Space in name", "my app", true},
+		{"Colon in name", "myapp:prod", true},
+		{"Other service ARN", "arn:aws:ssm:eu-west-2:123456789012:parameter/db", true},
+		{"Invalid stage", "myapp/db?stage=AWS CURRENT", true},
+		{"Too long", strings.Repeat("a", 513), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSecretsManagerPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSecretsManagerPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSecretsManagerProviderGet(t *testing.T) {
+	client := newFakeSecretsManager(map[string]string{
+		"myapp/prod/api-key": "plain-value",
+		"myapp/prod/db":      `{"username":"app","password":"s3cret","port":5432,"tls":{"mode":"require"}}`,
+	})
+	client.secrets["myapp/prod/db"]["AWSPREVIOUS"] = `{"password":"old"}`
+	client.binary["myapp/prod/cert"] = true
+	provider := &secretsManagerProvider{client: client}
+
+	tests := []struct {
+		path         string
+		want         string
+		wantNotFound bool
+		wantErr      bool
+	}{
+		{path: "myapp/prod/api-key", want: "plain-value"},
+		{path: "myapp/prod/db#password", want: "s3cret"},
+		{path: "myapp/prod/db#port", want: "5432"},
+		{path: "myapp/prod/db#tls", want: `{"mode":"require"}`},
+		{path: "myapp/prod/db?stage=AWSPREVIOUS#password", want: "old"},
+		{path: "myapp/prod/db#missing", wantNotFound: true},
+		{path: "myapp/prod/missing", wantNotFound: true},
+		{path: "myapp/prod/api-key#field", wantErr: true},
+		{path: "myapp/prod/cert", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := provider.Get(context.Background(), tt.path)
+			if tt.wantNotFound {
+				if !errors.Is(err, errSecretNotFound) {
+					t.Errorf("Get() error = %v, want errSecretNotFound", err)
+				}
+				return
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Get() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretsManagerProviderFetchReadsEachSecretOnce(t *testing.T) {
+	client := newFakeSecretsManager(map[string]string{
+		"myapp/prod/db": `{"username":"app","password":"s3cret","host":"db.internal"}`,
+	})
+	provider := &secretsManagerProvider{client: client}
+
+	paramMap := ParameterMap{
+		"DB_USER":     "myapp/prod/db#username",
+		"DB_PASSWORD": "myapp/prod/db#password",
+		"DB_HOST":     "myapp/prod/db#host",
+		"DB_PORT":     "myapp/prod/db#port",
+		"API_KEY":     "myapp/prod/api-key",
+	}
+
+	envVars, err := fetchParameters(context.Background(), provider, paramMap, 1)
+	if err != nil {
+		t.Fatalf("fetchParameters() error = %v", err)
+	}
+
+	if client.getSecretValueCalls != 2 {
+		t.Errorf("Expected 2 GetSecretValue calls, got %d", client.getSecretValueCalls)
+	}
+	expected := map[string]string{"DB_USER": "app", "DB_PASSWORD": "s3cret", "DB_HOST": "db.internal"}
+	if len(envVars) != len(expected) {
+		t.Errorf("Expected %d parameters, got %v", len(expected), envVars)
+	}
+	for key, want := range expected {
+		if envVars[key] != want {
+			t.Errorf("%s = %q, want %q", key, envVars[key], want)
+		}
+	}
+}
+
+func TestSecretsManagerProviderPut(t *testing.T) {
+	client := newFakeSecretsManager(map[string]string{
+		"myapp/prod/api-key": "old",
+		"myapp/prod/db":      `{"username":"app","password":"old","port":5432}`,
+	})
+	provider := &secretsManagerProvider{client: client}
+	ctx := context.Background()
+
+	puts := map[string]string{
+		"myapp/prod/api-key":                 "new",
+		"myapp/prod/db#password":             "new-password",
+		"myapp/prod/new-secret":              "created",
+		"myapp/prod/new-json#token":          "abc",
+		"myapp/prod/api-key?stage=candidate": "next",
+	}
+	for path, value := range puts {
+		if err := provider.Put(ctx, path, value); err != nil {
+			t.Fatalf("Put(%q) error = %v", path, err)
+		}
+	}
+
+	checks := map[string]string{
+		"myapp/prod/api-key":                 "new",
+		"myapp/prod/api-key?stage=candidate": "next",
+		"myapp/prod/db#username":             "app",
+		"myapp/prod/db#password":             "new-password",
+		"myapp/prod/db#port":                 "5432",
+		"myapp/prod/new-secret":              "created",
+		"myapp/prod/new-json":                `{"token":"abc"}`,
+	}
+	for path, want := range checks {
+		got, err := provider.Get(ctx, path)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", path, err)
+		}
+		if got != want {
+			t.Errorf("Get(%q) = %q, want %q", path, got, want)
+		}
+	}
+
+	if client.createSecretCalls != 2 {
+		t.Errorf("Expected 2 CreateSecret calls, got %d", client.createSecretCalls)
+	}
+
+	if err := provider.Put(ctx, "myapp/prod/other?stage=candidate", "x"); err == nil {
+		t.Error("Expected an error creating a secret with a version stage")
+	}
+}
+
+func TestSecretsManagerProviderPushWritesEachSecretOnce(t *testing.T) {
+	client := newFakeSecretsManager(map[string]string{"myapp/prod/db": `{"username":"app"}`})
+	provider := &secretsManagerProvider{client: client}
+
+	envVars := make(map[string]string)
+	paramMap := make(ParameterMap)
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("KEY_%02d", i)
+		envVars[key] = fmt.Sprintf("value-%02d", i)
+		paramMap[key] = fmt.Sprintf("myapp/prod/db#key-%02d", i)
+	}
+	envVars["TOKEN"], paramMap["TOKEN"] = "abc", "myapp/prod/new#token"
+	envVars["SECRET"], paramMap["SECRET"] = "xyz", "myapp/prod/new#secret"
+
+	if _, err := pushParameters(context.Background(), provider, envVars, paramMap, 8); err != nil {
+		t.Fatalf("pushParameters() error = %v", err)
+	}
+
+	if client.putSecretValueCalls != 2 || client.createSecretCalls != 1 {
+		t.Errorf("Expected one write per secret, got %d PutSecretValue and %d CreateSecret calls", client.putSecretValueCalls, client.createSecretCalls)
+	}
+
+	var fields map[string]string
+	if err := json.Unmarshal([]byte(client.secrets["myapp/prod/db"]["AWSCURRENT"]), &fields); err != nil {
+		t.Fatalf("Failed to decode secret: %v", err)
+	}
+	if len(fields) != 21 || fields["username"] != "app" {
+		t.Errorf("Expected all 20 keys to be written next to username, got %v", fields)
+	}
+	if got := client.secrets["myapp/prod/new"]["AWSCURRENT"]; got != `{"secret":"xyz","token":"abc"}` {
+		t.Errorf("new secret = %s", got)
+	}
+}
+
+func TestSecretsManagerProviderDelete(t *testing.T) {
+	client := newFakeSecretsManager(map[string]string{
+		"myapp/prod/api-key": "value",
+		"myapp/prod/db":      `{"username":"app","password":"s3cret"}`,
+	})
+	provider := &secretsManagerProvider{client: client}
+	ctx := context.Background()
+
+	if err := provider.Delete(ctx, "myapp/prod/db#password"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := client.secrets["myapp/prod/db"]["AWSCURRENT"]; got != `{"username":"app"}` {
+		t.Errorf("secret = %s, want only the username left", got)
+	}
+	if err := provider.Delete(ctx, "myapp/prod/db#password"); !errors.Is(err, errSecretNotFound) {
+		t.Errorf("Delete() of a missing key error = %v, want errSecretNotFound", err)
+	}
+
+	if err := provider.Delete(ctx, "myapp/prod/api-key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, exists := client.secrets["myapp/prod/api-key"]; exists {
+		t.Error("Expected the secret to be deleted")
+	}
+}
+
+func TestSecretsManagerProviderList(t *testing.T) {
+	provider := &secretsManagerProvider{client: newFakeSecretsManager(map[string]string{
+		"myapp/prod/db-password": "a",
+		"myapp/prod/api-key":     "b",
+		"myapp/prod/cache-url":   "c",
+		"MyApp/Prod/other":       "d",
+		"myapp/test/db-password": "e",
+	})}
+
+	names, err := provider.List(context.Background(), "myapp/prod/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	expected := []string{"myapp/prod/api-key", "myapp/prod/cache-url", "myapp/prod/db-password"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+}
+
+func TestTranslateSecretsManagerError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantNotFound bool
+		wantAuth     bool
+	}{
+		{"Secret not found", &smtypes.ResourceNotFoundException{}, true, false},
+		{"Access denied", &smithy.GenericAPIError{Code: "AccessDeniedException"}, false, true},
+		{"Throttled", &smithy.GenericAPIError{Code: "ThrottlingException"}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateSecretsManagerError(tt.err)
+			if errors.Is(err, errSecretNotFound) != tt.wantNotFound {
+				t.Errorf("translateSecretsManagerError() not found = %v, want %v", errors.Is(err, errSecretNotFound), tt.wantNotFound)
+			}
+			if isAuthError(err) != tt.wantAuth {
+				t.Errorf("translateSecretsManagerError() auth = %v, want %v", isAuthError(err), tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestAWSProviderRoutesSecretsManagerEntries(t *testing.T) {
+	ssmClient := newFakeSSM(map[string]string{"/myapp/prod/api-key": "from-ssm"})
+	smClient := newFakeSecretsManager(map[string]string{
+		"myapp/prod/db":          `{"password":"from-sm"}`,
+		"myapp/prod/certs/chain": "chain",
+	})
+	provider := &awsProvider{ssm: &ssmProvider{client: ssmClient}, sm: &secretsManagerProvider{client: smClient}}
+	ctx := context.Background()
+
+	if provider.Name() != "SSM" {
+		t.Errorf("Name() = %q before using a map with sm: entries, want SSM", provider.Name())
+	}
+
+	paramMap := ParameterMap{
+		"API_KEY":     "/myapp/prod/api-key",
+		"DB_PASSWORD": "sm:myapp/prod/db#password",
+	}
+	if err := provider.ValidateMap(paramMap); err != nil {
+		t.Fatalf("ValidateMap() error = %v", err)
+	}
+	if provider.Name() != "SSM" {
+		t.Errorf("Name() = %q after ValidateMap(), want SSM", provider.Name())
+	}
+	useParameterMap(provider, paramMap)
+	if provider.Name() != "SSM and Secrets Manager" {
+		t.Errorf("Name() = %q, want SSM and Secrets Manager", provider.Name())
+	}
+
+	envVars, err := fetchParameters(ctx, provider, paramMap, 2)
+	if err != nil {
+		t.Fatalf("fetchParameters() error = %v", err)
+	}
+	if envVars["API_KEY"] != "from-ssm" || envVars["DB_PASSWORD"] != "from-sm" {
+		t.Errorf("fetchParameters() = %v", envVars)
+	}
+
+	if err := provider.Put(ctx, "sm:myapp/prod/db#password", "updated"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := smClient.secrets["myapp/prod/db"]["AWSCURRENT"]; got != `{"password":"updated"}` {
+		t.Errorf("secret = %s after Put()", got)
+	}
+	if ssmClient.putParameterCalls != 0 {
+		t.Errorf("Expected no SSM writes, got %d", ssmClient.putParameterCalls)
+	}
+
+	paths, err := provider.List(ctx, "sm:myapp/prod/certs/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "sm:myapp/prod/certs/chain" {
+		t.Errorf("List() = %v, want [sm:myapp/prod/certs/chain]", paths)
+	}
+}
+
+func TestAWSProviderValidateMap(t *testing.T) {
+	provider := &awsProvider{ssm: &ssmProvider{}, sm: &secretsManagerProvider{}}
+
+	tests := []struct {
+		name     string
+		paramMap ParameterMap
+		wantErr  bool
+	}{
+		{"SSM only", ParameterMap{"API_KEY": "/myapp/prod/api-key"}, false},
+		{"Secrets Manager only", ParameterMap{"API_KEY": "sm:myapp/prod/api-key"}, false},
+		{"Mixed", ParameterMap{"API_KEY": "/myapp/prod/api-key", "DB": "sm:myapp/prod/db#password"}, false},
+		{"Secrets Manager prefix", ParameterMap{"*": "sm:myapp/prod/"}, false},
+		{"Secrets Manager name in SSM", ParameterMap{"API_KEY": "myapp/prod/api-key"}, true},
+		{"SSM path rules do not apply to Secrets Manager", ParameterMap{"API_KEY": "sm:myapp+prod@eu"}, false},
+		{"Invalid Secrets Manager name", ParameterMap{"API_KEY": "sm:my app"}, true},
+		{"Invalid env var name", ParameterMap{"1KEY": "sm:myapp/prod/api-key"}, true},
+		{"Empty", ParameterMap{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := provider.ValidateMap(tt.paramMap)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAWSProviderDefaultSecretsManager(t *testing.T) {
+	smClient := newFakeSecretsManager(map[string]string{
+		"myapp/prod/db":      `{"password":"s3cret"}`,
+		"myapp/prod/api-key": "my-api-key",
+	})
+	provider := &awsProvider{ssm: &ssmProvider{client: newFakeSSM(nil)}, sm: &secretsManagerProvider{client: smClient}, defaultSM: true}
+	ctx := context.Background()
+
+	// With --secrets-manager the sm: prefix is optional
+	paramMap := ParameterMap{
+		"API_KEY":     "myapp/prod/api-key",
+		"DB_PASSWORD": "sm:myapp/prod/db#password",
+	}
+	if err := provider.ValidateMap(paramMap); err != nil {
+		t.Fatalf("ValidateMap() error = %v", err)
+	}
+	if provider.Name() != "Secrets Manager" {
+		t.Errorf("Name() = %q, want Secrets Manager", provider.Name())
+	}
+
+	envVars, err := fetchParameters(ctx, provider, paramMap, 2)
+	if err != nil {
+		t.Fatalf("fetchParameters() error = %v", err)
+	}
+	if envVars["API_KEY"] != "my-api-key" || envVars["DB_PASSWORD"] != "s3cret" {
+		t.Errorf("fetchParameters() = %v", envVars)
+	}
+
+	if err := provider.Put(ctx, "sm:myapp/prod/api-key", "updated"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := smClient.secrets["myapp/prod/api-key"]["AWSCURRENT"]; got != "updated" {
+		t.Errorf("secret = %s after Put()", got)
+	}
+
+	for prefix, want := range map[string]string{"myapp/prod/api": "myapp/prod/api-key", "sm:myapp/prod/api": "sm:myapp/prod/api-key"} {
+		paths, err := provider.List(ctx, prefix)
+		if err != nil {
+			t.Fatalf("List(%q) error = %v", prefix, err)
+		}
+		if len(paths) != 1 || paths[0] != want {
+			t.Errorf("List(%q) = %v, want [%s]", prefix, paths, want)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memProvider is an in-memory SecretProvider used by tests
+type memProvider struct {
+	mu      sync.Mutex
+	values  map[string]string
+	puts    []string
+	getErrs map[string]error
+}
+
+func newMemProvider(values map[string]string) *memProvider {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	return &memProvider{values: values, getErrs: make(map[string]error)}
+}
+
+func (p *memProvider) Name() string { return "Mem" }
+
+func (p *memProvider) ValidateMap(paramMap ParameterMap) error { return validateParameterMap(paramMap) }
+
+func (p *memProvider) Get(ctx context.Context, path string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err, ok := p.getErrs[path]; ok {
+		return "", err
+	}
+	value, ok := p.values[path]
+	if !ok {
+		return "", errSecretNotFound
+	}
+	return value, nil
+}
+
+func (p *memProvider) Put(ctx context.Context, path, value string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.values[path] = value
+	p.puts = append(p.puts, path)
+	return nil
+}
+
+func (p *memProvider) List(ctx context.Context, prefix string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var paths []string
+	for path := range p.values {
+		if strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (p *memProvider) Delete(ctx context.Context, path string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.values[path]; !ok {
+		return errSecretNotFound
+	}
+	delete(p.values, path)
+	return nil
+}
+
+func TestFetchParametersSkipsMissing(t *testing.T) {
+	provider := newMemProvider(map[string]string{
+		"/myapp/dev/db-password": "secret123",
+	})
+	paramMap := ParameterMap{
+		"DB_PASSWORD": "/myapp/dev/db-password",
+		"API_KEY":     "/myapp/dev/api-key",
+	}
+
+	envVars, err := fetchParameters(context.Background(), provider, paramMap, 1)
+	if err != nil {
+		t.Fatalf("fetchParameters() error = %v", err)
+	}
+
+	if len(envVars) != 1 || envVars["DB_PASSWORD"] != "secret123" {
+		t.Errorf("Expected only DB_PASSWORD=secret123, got %v", envVars)
+	}
+}
+
+func TestFetchParametersFailsOnProviderError(t *testing.T) {
+	provider := newMemProvider(nil)
+	provider.getErrs["/myapp/dev/api-key"] = errors.New("access denied")
+	paramMap := ParameterMap{"API_KEY": "/myapp/dev/api-key"}
+
+	_, err := fetchParameters(context.Background(), provider, paramMap, 1)
+	if err == nil {
+		t.Fatal("Expected error from fetchParameters, got nil")
+	}
+	if strings.Contains(err.Error(), "/myapp/dev/api-key") {
+		t.Errorf("Error should not expose the remote path: %v", err)
+	}
+	if !strings.Contains(err.Error(), "failed to get parameter for API_KEY") {
+		t.Errorf("Error should name the failing key: %v", err)
+	}
+}
+
+func TestFetchParametersConcurrent(t *testing.T) {
+	values := make(map[string]string)
+	paramMap := make(ParameterMap)
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("KEY_%02d", i)
+		path := fmt.Sprintf("/myapp/dev/key-%02d", i)
+		paramMap[key] = path
+		if i%5 != 0 {
+			values[path] = fmt.Sprintf("value-%02d", i)
+		}
+	}
+	provider := newMemProvider(values)
+
+	envVars, err := fetchParameters(context.Background(), provider, paramMap, 8)
+	if err != nil {
+		t.Fatalf("fetchParameters() error = %v", err)
+	}
+
+	if len(envVars) != 40 {
+		t.Errorf("Expected 40 parameters, got %d", len(envVars))
+	}
+	for key, path := range paramMap {
+		want, exists := values[path]
+		if got, ok := envVars[key]; ok != exists || got != want {
+			t.Errorf("For key %s, expected %q (exists %v), got %q (exists %v)", key, want, exists, got, ok)
+		}
+	}
+}
+
+// blockingProvider blocks every Get until the context is cancelled, except for failPath
+type blockingProvider struct {
+	*memProvider
+	failPath string
+}
+
+func (p *blockingProvider) Get(ctx context.Context, path string) (string, error) {
+	if path == p.failPath {
+		return "", errors.New("access denied")
+	}
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestFetchParametersCancelsOnError(t *testing.T) {
+	provider := &blockingProvider{memProvider: newMemProvider(nil), failPath: "/myapp/dev/key-02"}
+	paramMap := make(ParameterMap)
+	for i := 0; i < 10; i++ {
+		paramMap[fmt.Sprintf("KEY_%02d", i)] = fmt.Sprintf("/myapp/dev/key-%02d", i)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := fetchParameters(context.Background(), provider, paramMap, 4)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "KEY_02") {
+			t.Errorf("Expected error for KEY_02, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetchParameters did not cancel in-flight requests after an error")
+	}
+}
+
+func TestPushParameters(t *testing.T) {
+	provider := newMemProvider(nil)
+	envVars := map[string]string{
+		"DB_PASSWORD": "secret123",
+		"UNMAPPED":    "ignored",
+	}
+	paramMap := ParameterMap{
+		"DB_PASSWORD": "/myapp/dev/db-password",
+		"API_KEY":     "/myapp/dev/api-key",
+	}
+
+	if _, err := pushParameters(context.Background(), provider, envVars, paramMap, 1); err != nil {
+		t.Fatalf("pushParameters() error = %v", err)
+	}
+
+	if len(provider.values) != 1 || provider.values["/myapp/dev/db-password"] != "secret123" {
+		t.Errorf("Expected only /myapp/dev/db-password to be pushed, got %v", provider.values)
+	}
+}
+
+func TestSyncParametersForce(t *testing.T) {
+	tmpDir := t.TempDir()
+	envFile := filepath.Join(tmpDir, ".env")
+
+	provider := newMemProvider(map[string]string{
+		"/myapp/dev/db-password": "new_password",
+		"/myapp/dev/api-key":     "same_key",
+	})
+	paramMap := ParameterMap{
+		"DB_PASSWORD": "/myapp/dev/db-password",
+		"API_KEY":     "/myapp/dev/api-key",
+	}
+	localEnvVars := map[string]string{
+		"DB_PASSWORD": "old_password",
+		"API_KEY":     "same_key",
+		"LOCAL_ONLY":  "kept",
+	}
+	if err := writeEnvFile(envFile, localEnvVars, false); err != nil {
+		t.Fatalf("writeEnvFile() error = %v", err)
+	}
+
+	err := syncParameters(context.Background(), provider, localEnvVars, paramMap, nil, envFile, syncOptions{Force: true, Concurrency: 1})
+	if err != nil {
+		t.Fatalf("syncParameters() error = %v", err)
+	}
+
+	updatedVars, err := readEnvFile(envFile)
+	if err != nil {
+		t.Fatalf("Failed to read updated .env file: %v", err)
+	}
+
+	expected := map[string]string{
+		"DB_PASSWORD": "new_password",
+		"API_KEY":     "same_key",
+		"LOCAL_ONLY":  "kept",
+	}
+	for key, want := range expected {
+		if got := updatedVars[key]; got != want {
+			t.Errorf("For key %s, expected %q, got %q", key, want, got)
+		}
+	}
+}
+
+func TestSyncParametersAppliesDefaults(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	localEnvVars := map[string]string{"API_KEY": "my-api-key"}
+	if err := writeEnvFile(envFile, localEnvVars, false); err != nil {
+		t.Fatalf("writeEnvFile() error = %v", err)
+	}
+
+	provider := newMemProvider(map[string]string{"/myapp/dev/api-key": "my-api-key"})
+	paramMap := ParameterMap{"API_KEY": "/myapp/dev/api-key", "DB_PORT": "/myapp/dev/db-port"}
+	port := "5432"
+	specs := ParameterSpecs{"DB_PORT": {Path: "/myapp/dev/db-port", Default: &port}}
+
+	err := syncParameters(context.Background(), provider, localEnvVars, paramMap, specs, envFile, syncOptions{Force: true, Concurrency: 1})
+	if err != nil {
+		t.Fatalf("syncParameters() error = %v", err)
+	}
+
+	updatedVars, err := readEnvFile(envFile)
+	if err != nil {
+		t.Fatalf("Failed to read updated .env file: %v", err)
+	}
+	if updatedVars["DB_PORT"] != "5432" {
+		t.Errorf("Expected DB_PORT to get its default, got %q", updatedVars["DB_PORT"])
+	}
+}
+
+func TestSyncParametersLocalToRemote(t *testing.T) {
+	provider := newMemProvider(map[string]string{
+		"/myapp/dev/db-password": "remote_password",
+		"/myapp/dev/api-key":     "same_key",
+	})
+	paramMap := ParameterMap{
+		"DB_PASSWORD":  "/myapp/dev/db-password",
+		"API_KEY":      "/myapp/dev/api-key",
+		"NEW_KEY":      "/myapp/dev/new-key",
+		"DATABASE_URL": "/myapp/dev/database-url",
+	}
+	localEnvVars := map[string]string{
+		"DB_PASSWORD": "local_password",
+		"API_KEY":     "same_key",
+		"NEW_KEY":     "new_value",
+	}
+
+	// Differences are prompted in key order: DB_PASSWORD, then NEW_KEY
+	promptInput = strings.NewReader("n\ny\n")
+	defer func() { promptInput = os.Stdin }()
+
+	opts := syncOptions{Concurrency: 1, Direction: syncLocalToRemote}
+	err := syncParameters(context.Background(), provider, localEnvVars, paramMap, nil, ".env", opts)
+	if err != nil {
+		t.Fatalf("syncParameters() error = %v", err)
+	}
+
+	if len(provider.puts) != 1 || provider.puts[0] != "/myapp/dev/new-key" {
+		t.Errorf("Expected only /myapp/dev/new-key to be pushed, got %v", provider.puts)
+	}
+	if provider.values["/myapp/dev/db-password"] != "remote_password" {
+		t.Errorf("Expected declined DB_PASSWORD to be left unchanged, got %q", provider.values["/myapp/dev/db-password"])
+	}
+}
+
+func TestFindPushDifferences(t *testing.T) {
+	localEnvVars := map[string]string{
+		"DB_PASSWORD": "local",
+		"API_KEY":     "same",
+		"NEW_KEY":     "new",
+	}
+	remoteEnvVars := map[string]string{
+		"DB_PASSWORD":  "remote",
+		"API_KEY":      "same",
+		"DATABASE_URL": "remote_only",
+	}
+	paramMap := ParameterMap{
+		"DB_PASSWORD":  "/myapp/dev/db-password",
+		"API_KEY":      "/myapp/dev/api-key",
+		"NEW_KEY":      "/myapp/dev/new-key",
+		"DATABASE_URL": "/myapp/dev/database-url",
+	}
+
+	differences := findPushDifferences(localEnvVars, remoteEnvVars, paramMap)
+
+	if len(differences) != 2 {
+		t.Fatalf("Expected 2 differences, got %d: %v", len(differences), differences)
+	}
+	if differences[0].Key != "DB_PASSWORD" || !differences[0].ExistsRemote {
+		t.Errorf("Expected DB_PASSWORD to differ remotely, got %+v", differences[0])
+	}
+	if differences[1].Key != "NEW_KEY" || differences[1].ExistsRemote {
+		t.Errorf("Expected NEW_KEY to be missing remotely, got %+v", differences[1])
+	}
+}
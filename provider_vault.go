@@ -0,0 +1,532 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// vaultDefaultField is the field of a Vault secret used when a path has no #field selector
+const vaultDefaultField = "value"
+
+// vaultAppRoleMount is the path the AppRole auth method is mounted at
+const vaultAppRoleMount = "approle"
+
+// vaultBatchSize is the number of paths fetched together, so that paths reading different
+// fields of the same secret share one request
+const vaultBatchSize = 10
+
+// vaultConfig holds the settings for connecting to HashiCorp Vault
+type vaultConfig struct {
+	// Address is the Vault server URL, e.g. https://vault.example.com:8200
+	Address string
+	// Mount is the path the KV secrets engine is mounted at, e.g. secret
+	Mount string
+	// KVVersion is the version of the KV secrets engine, 1 or 2
+	KVVersion int
+	// Namespace is the Vault Enterprise namespace, if any
+	Namespace string
+
+	// Token authenticates directly; otherwise RoleID and SecretID log in with AppRole
+	Token    string
+	RoleID   string
+	SecretID string
+}
+
+// vaultRef is a parsed Vault path of the form path[?version=N][#field]
+type vaultRef struct {
+	Path string
+	// Version is the KV v2 version to read, or 0 for the latest
+	Version int
+	Field   string
+}
+
+// parseVaultRef splits a Vault path into the secret path, version and field, defaulting the
+// field to vaultDefaultField
+func parseVaultRef(path string) (vaultRef, error) {
+	rest, field, hasField := strings.Cut(path, "#")
+	if hasField && field == "" {
+		return vaultRef{}, fmt.Errorf("empty field after #")
+	}
+	if !hasField {
+		field = vaultDefaultField
+	}
+
+	secretPath, query, hasQuery := strings.Cut(rest, "?")
+	ref := vaultRef{Path: secretPath, Field: field}
+	if hasQuery {
+		version, ok := strings.CutPrefix(query, "version=")
+		if !ok {
+			return vaultRef{}, fmt.Errorf("expected ?version=N after the secret path")
+		}
+		n, err := strconv.Atoi(version)
+		if err != nil || n < 1 {
+			return vaultRef{}, fmt.Errorf("version must be a positive integer")
+		}
+		ref.Version = n
+	}
+
+	return ref, nil
+}
+
+// vaultToken returns the Vault token from $VAULT_TOKEN or, like the vault CLI, ~/.vault-token
+func vaultToken() string {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// vaultProvider is a SecretProvider backed by a HashiCorp Vault KV secrets engine, version 1
+// or 2. Paths name a secret within the mount and select one of its fields.
+type vaultProvider struct {
+	client *http.Client
+	config vaultConfig
+
+	// tokenMu guards token, which is obtained on first use when logging in with AppRole
+	tokenMu sync.Mutex
+	token   string
+}
+
+// newVaultProvider creates a Vault provider, checking the configuration but not contacting Vault
+func newVaultProvider(config vaultConfig, client *http.Client) (*vaultProvider, error) {
+	address, err := url.Parse(config.Address)
+	if err != nil || (address.Scheme != "http" && address.Scheme != "https") || address.Host == "" {
+		return nil, fmt.Errorf("invalid Vault address %q: expected a URL such as https://vault.example.com:8200", config.Address)
+	}
+	config.Address = strings.TrimSuffix(config.Address, "/")
+
+	config.Mount = strings.Trim(config.Mount, "/")
+	if err := validateVaultSecretPath(config.Mount); err != nil {
+		return nil, fmt.Errorf("invalid Vault mount %q: %w", config.Mount, err)
+	}
+
+	if config.KVVersion != 1 && config.KVVersion != 2 {
+		return nil, fmt.Errorf("invalid KV version %d: expected 1 or 2", config.KVVersion)
+	}
+
+	if config.Token == "" && (config.RoleID == "" || config.SecretID == "") {
+		return nil, fmt.Errorf("no Vault credentials: set VAULT_TOKEN, or VAULT_ROLE_ID and VAULT_SECRET_ID to log in with AppRole")
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	// AppRole takes precedence so a stale token in the environment is not used by mistake
+	token := config.Token
+	if config.RoleID != "" && config.SecretID != "" {
+		token = ""
+	}
+
+	return &vaultProvider{client: client, config: config, token: token}, nil
+}
+
+func (p *vaultProvider) Name() string {
+	return "Vault"
+}
+
+func (p *vaultProvider) ValidateMap(paramMap ParameterMap) error {
+	return validateVaultParameterMap(paramMap, p.config.KVVersion)
+}
+
+// vaultErrorResponse is the body Vault returns with an error status
+type vaultErrorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+// vaultStatusError is an error status from Vault other than 401, 403 and 404
+type vaultStatusError struct {
+	StatusCode int
+	Errors     []string
+}
+
+func (e *vaultStatusError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("Vault returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("Vault returned %d: %s", e.StatusCode, strings.Join(e.Errors, "; "))
+}
+
+// send makes a request to the Vault HTTP API and decodes the JSON response into out, if given.
+// A 404 response is reported as errSecretNotFound.
+func (p *vaultProvider) send(ctx context.Context, method, apiPath, token string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode Vault request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.config.Address+"/v1/"+apiPath, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create Vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Request", "true")
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if p.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.config.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errSecretNotFound
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return &authError{fmt.Errorf("Vault authorization failed: the token is missing, expired or not allowed to access this path by its policies")}
+	case resp.StatusCode >= 400:
+		var errResp vaultErrorResponse
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return &vaultStatusError{StatusCode: resp.StatusCode, Errors: errResp.Errors}
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	// Keep numbers as written so large integers survive a read-modify-write
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(out); err != nil {
+		return fmt.Errorf("failed to decode Vault response: %w", err)
+	}
+	return nil
+}
+
+// do makes an authenticated request to the Vault HTTP API
+func (p *vaultProvider) do(ctx context.Context, method, apiPath string, body, out any) error {
+	token, err := p.authToken(ctx)
+	if err != nil {
+		return err
+	}
+	return p.send(ctx, method, apiPath, token, body, out)
+}
+
+// authToken returns the client token, logging in with AppRole the first time if needed
+func (p *vaultProvider) authToken(ctx context.Context) (string, error) {
+	p.tokenMu.Lock()
+	defer p.tokenMu.Unlock()
+
+	if p.token != "" {
+		return p.token, nil
+	}
+
+	var login struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	body := map[string]string{"role_id": p.config.RoleID, "secret_id": p.config.SecretID}
+	err := p.send(ctx, http.MethodPost, "auth/"+vaultAppRoleMount+"/login", "", body, &login)
+	if err != nil {
+		// Vault answers a wrong role or secret ID with 400 rather than 403
+		var statusErr *vaultStatusError
+		if isAuthError(err) || errors.Is(err, errSecretNotFound) ||
+			errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadRequest {
+			return "", &authError{fmt.Errorf("Vault AppRole login failed: check VAULT_ROLE_ID and VAULT_SECRET_ID")}
+		}
+		return "", fmt.Errorf("Vault AppRole login failed: %w", err)
+	}
+	if login.Auth.ClientToken == "" {
+		return "", &authError{fmt.Errorf("Vault AppRole login returned no token")}
+	}
+
+	p.token = login.Auth.ClientToken
+	return p.token, nil
+}
+
+// readSecret returns the fields of a secret, and for KV v2 the version that was read
+func (p *vaultProvider) readSecret(ctx context.Context, secretPath string, version int) (map[string]any, int, error) {
+	if p.config.KVVersion == 1 {
+		var resp struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := p.do(ctx, http.MethodGet, p.config.Mount+"/"+secretPath, nil, &resp); err != nil {
+			return nil, 0, err
+		}
+		return resp.Data, 0, nil
+	}
+
+	apiPath := p.config.Mount + "/data/" + secretPath
+	if version > 0 {
+		apiPath += "?version=" + strconv.Itoa(version)
+	}
+	var resp struct {
+		Data struct {
+			Data     map[string]any `json:"data"`
+			Metadata struct {
+				Version int `json:"version"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+	if err := p.do(ctx, http.MethodGet, apiPath, nil, &resp); err != nil {
+		return nil, 0, err
+	}
+	// A deleted or destroyed version has no data
+	if resp.Data.Data == nil {
+		return nil, 0, errSecretNotFound
+	}
+	return resp.Data.Data, resp.Data.Metadata.Version, nil
+}
+
+// writeSecret stores all fields of a secret. For KV v2 a non-zero cas makes the write fail if
+// the secret changed since that version was read.
+func (p *vaultProvider) writeSecret(ctx context.Context, secretPath string, fields map[string]any, cas int) error {
+	if p.config.KVVersion == 1 {
+		return p.do(ctx, http.MethodPost, p.config.Mount+"/"+secretPath, fields, nil)
+	}
+
+	body := map[string]any{"data": fields}
+	if cas > 0 {
+		body["options"] = map[string]int{"cas": cas}
+	}
+	return p.do(ctx, http.MethodPost, p.config.Mount+"/data/"+secretPath, body, nil)
+}
+
+func (p *vaultProvider) Get(ctx context.Context, path string) (string, error) {
+	ref, err := parseVaultRef(path)
+	if err != nil {
+		return "", err
+	}
+
+	fields, _, err := p.readSecret(ctx, ref.Path, ref.Version)
+	if err != nil {
+		return "", err
+	}
+	return vaultFieldValue(fields, ref.Field)
+}
+
+func (p *vaultProvider) BatchSize() int {
+	return vaultBatchSize
+}
+
+// GetBatch reads each distinct secret and version once and extracts every requested field
+// from it. Secrets and fields that do not exist are omitted from the result.
+func (p *vaultProvider) GetBatch(ctx context.Context, paths []string) (map[string]string, error) {
+	type secretVersion struct {
+		path    string
+		version int
+	}
+
+	values := make(map[string]string, len(paths))
+	secrets := make(map[secretVersion]map[string]any)
+	missing := make(map[secretVersion]bool)
+
+	for _, path := range paths {
+		ref, err := parseVaultRef(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key := secretVersion{ref.Path, ref.Version}
+		if missing[key] {
+			continue
+		}
+		fields, fetched := secrets[key]
+		if !fetched {
+			fields, _, err = p.readSecret(ctx, ref.Path, ref.Version)
+			if errors.Is(err, errSecretNotFound) {
+				missing[key] = true
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			secrets[key] = fields
+		}
+
+		value, err := vaultFieldValue(fields, ref.Field)
+		if errors.Is(err, errSecretNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[path] = value
+	}
+
+	return values, nil
+}
+
+// Put sets one field of a secret, keeping its other fields
+func (p *vaultProvider) Put(ctx context.Context, path, value string) error {
+	ref, err := parseVaultRef(path)
+	if err != nil {
+		return err
+	}
+	if ref.Version > 0 {
+		return fmt.Errorf("cannot write to a specific version; remove ?version from the path")
+	}
+	return p.putFields(ctx, ref.Path, map[string]string{ref.Field: value})
+}
+
+// PutBatch sets several fields, writing each secret once so that a push creates a single new
+// KV v2 version per secret rather than one per field
+func (p *vaultProvider) PutBatch(ctx context.Context, values map[string]string) error {
+	var secretPaths []string
+	fieldsBySecret := make(map[string]map[string]string)
+	for _, path := range sortedKeys(values) {
+		ref, err := parseVaultRef(path)
+		if err != nil {
+			return err
+		}
+		if ref.Version > 0 {
+			return fmt.Errorf("cannot write to a specific version of %s; remove ?version from the path", ref.Path)
+		}
+
+		if fieldsBySecret[ref.Path] == nil {
+			fieldsBySecret[ref.Path] = make(map[string]string)
+			secretPaths = append(secretPaths, ref.Path)
+		}
+		fieldsBySecret[ref.Path][ref.Field] = values[path]
+	}
+
+	for _, secretPath := range secretPaths {
+		if err := p.putFields(ctx, secretPath, fieldsBySecret[secretPath]); err != nil {
+			return fmt.Errorf("failed to put %s: %w", secretPath, err)
+		}
+	}
+	return nil
+}
+
+// putFields sets fields of a secret, keeping its other fields, and creates the secret if it does
+// not exist. With KV v2 the update is check-and-set against the version that was read.
+func (p *vaultProvider) putFields(ctx context.Context, secretPath string, values map[string]string) error {
+	fields, version, err := p.readSecret(ctx, secretPath, 0)
+	if errors.Is(err, errSecretNotFound) {
+		fields = make(map[string]any)
+	} else if err != nil {
+		return err
+	}
+
+	for field, value := range values {
+		fields[field] = value
+	}
+	return p.writeSecret(ctx, secretPath, fields, version)
+}
+
+// List returns the paths of all secrets under prefix, descending into sub-folders
+func (p *vaultProvider) List(ctx context.Context, prefix string) ([]string, error) {
+	// Vault lists folders, so list the folder containing the prefix and filter by name
+	folder := ""
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		folder = prefix[:i+1]
+	}
+
+	var paths []string
+	pending := []string{folder}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+
+		apiPath := p.config.Mount + "/" + current
+		if p.config.KVVersion == 2 {
+			apiPath = p.config.Mount + "/metadata/" + current
+		}
+
+		var resp struct {
+			Data struct {
+				Keys []string `json:"keys"`
+			} `json:"data"`
+		}
+		err := p.do(ctx, "LIST", apiPath, nil, &resp)
+		if errors.Is(err, errSecretNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range resp.Data.Keys {
+			child := current + key
+			if !strings.HasPrefix(child, prefix) && !strings.HasPrefix(prefix, child) {
+				continue
+			}
+			if strings.HasSuffix(key, "/") {
+				pending = append(pending, child)
+			} else if strings.HasPrefix(child, prefix) {
+				paths = append(paths, child)
+			}
+		}
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Delete removes one field of a secret, deleting the secret when no fields are left. For KV v2
+// the latest version is soft-deleted and can be undeleted.
+func (p *vaultProvider) Delete(ctx context.Context, path string) error {
+	ref, err := parseVaultRef(path)
+	if err != nil {
+		return err
+	}
+
+	fields, version, err := p.readSecret(ctx, ref.Path, 0)
+	if err != nil {
+		return err
+	}
+	if _, exists := fields[ref.Field]; !exists {
+		return errSecretNotFound
+	}
+	delete(fields, ref.Field)
+
+	if len(fields) > 0 {
+		return p.writeSecret(ctx, ref.Path, fields, version)
+	}
+
+	apiPath := p.config.Mount + "/" + ref.Path
+	if p.config.KVVersion == 2 {
+		apiPath = p.config.Mount + "/data/" + ref.Path
+	}
+	return p.do(ctx, http.MethodDelete, apiPath, nil, nil)
+}
+
+// vaultFieldValue returns one field of a secret. String values are returned as is and any other
+// value as JSON. A missing field is reported as errSecretNotFound.
+func vaultFieldValue(fields map[string]any, field string) (string, error) {
+	value, exists := fields[field]
+	if !exists {
+		return "", errSecretNotFound
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode field %q: %w", field, err)
+	}
+	return string(data), nil
+}
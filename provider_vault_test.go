@@ -0,0 +1,577 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeVault is an httptest stand-in for the parts of the Vault HTTP API used by vaultProvider:
+// a KV secrets engine mounted at "secret" and the AppRole login endpoint
+type fakeVault struct {
+	mu        sync.Mutex
+	kvVersion int
+	token     string
+	roleID    string
+	secretID  string
+
+	// versions holds every version of each secret, oldest first; a nil version is deleted
+	versions map[string][]map[string]any
+
+	reads  int
+	logins int
+}
+
+func newFakeVault(t *testing.T, kvVersion int, secrets map[string]map[string]any) (*fakeVault, *httptest.Server) {
+	f := &fakeVault{
+		kvVersion: kvVersion,
+		token:     "root-token",
+		roleID:    "role",
+		secretID:  "secret",
+		versions:  make(map[string][]map[string]any),
+	}
+	for path, fields := range secrets {
+		f.versions[path] = []map[string]any{fields}
+	}
+
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	apiPath := strings.TrimPrefix(r.URL.Path, "/v1/")
+	if apiPath == "auth/approle/login" {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != f.roleID || body["secret_id"] != f.secretID {
+			writeVaultError(w, http.StatusBadRequest, "invalid role or secret ID")
+			return
+		}
+		f.logins++
+		writeVaultJSON(w, map[string]any{"auth": map[string]any{"client_token": f.token}})
+		return
+	}
+
+	if r.Header.Get("X-Vault-Token") != f.token {
+		writeVaultError(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	rest, ok := strings.CutPrefix(apiPath, "secret/")
+	if !ok {
+		writeVaultError(w, http.StatusNotFound, "no handler for route")
+		return
+	}
+
+	if f.kvVersion == 1 {
+		f.serveKV1(w, r, rest)
+	} else {
+		f.serveKV2(w, r, rest)
+	}
+}
+
+func (f *fakeVault) serveKV1(w http.ResponseWriter, r *http.Request, path string) {
+	switch r.Method {
+	case "LIST":
+		f.serveList(w, path)
+	case http.MethodGet:
+		f.reads++
+		fields := f.latest(path)
+		if fields == nil {
+			writeVaultError(w, http.StatusNotFound, "")
+			return
+		}
+		writeVaultJSON(w, map[string]any{"data": fields})
+	case http.MethodPost, http.MethodPut:
+		var fields map[string]any
+		json.NewDecoder(r.Body).Decode(&fields)
+		f.versions[path] = []map[string]any{fields}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		delete(f.versions, path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakeVault) serveKV2(w http.ResponseWriter, r *http.Request, rest string) {
+	if path, ok := strings.CutPrefix(rest, "metadata/"); ok && r.Method == "LIST" {
+		f.serveList(w, path)
+		return
+	}
+	path, ok := strings.CutPrefix(rest, "data/")
+	if !ok {
+		writeVaultError(w, http.StatusNotFound, "")
+		return
+	}
+	versions := f.versions[path]
+
+	switch r.Method {
+	case http.MethodGet:
+		f.reads++
+		version := len(versions)
+		if v := r.URL.Query().Get("version"); v != "" {
+			version, _ = strconv.Atoi(v)
+		}
+		if version < 1 || version > len(versions) || versions[version-1] == nil {
+			writeVaultError(w, http.StatusNotFound, "")
+			return
+		}
+		writeVaultJSON(w, map[string]any{"data": map[string]any{
+			"data":     versions[version-1],
+			"metadata": map[string]any{"version": version},
+		}})
+	case http.MethodPost, http.MethodPut:
+		var body struct {
+			Data    map[string]any `json:"data"`
+			Options struct {
+				CAS *int `json:"cas"`
+			} `json:"options"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Options.CAS != nil && *body.Options.CAS != len(versions) {
+			writeVaultError(w, http.StatusBadRequest, "check-and-set parameter did not match the current version")
+			return
+		}
+		f.versions[path] = append(versions, body.Data)
+		writeVaultJSON(w, map[string]any{"data": map[string]any{"version": len(f.versions[path])}})
+	case http.MethodDelete:
+		if len(versions) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		versions[len(versions)-1] = nil
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// serveList lists the keys directly under a folder, with sub-folders ending in /
+func (f *fakeVault) serveList(w http.ResponseWriter, folder string) {
+	seen := make(map[string]bool)
+	for path := range f.versions {
+		rest, ok := strings.CutPrefix(path, folder)
+		if !ok || f.latest(path) == nil && f.kvVersion == 1 {
+			continue
+		}
+		if i := strings.Index(rest, "/"); i >= 0 {
+			rest = rest[:i+1]
+		}
+		seen[rest] = true
+	}
+	if len(seen) == 0 {
+		writeVaultError(w, http.StatusNotFound, "")
+		return
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	writeVaultJSON(w, map[string]any{"data": map[string]any{"keys": keys}})
+}
+
+func (f *fakeVault) latest(path string) map[string]any {
+	versions := f.versions[path]
+	if len(versions) == 0 {
+		return nil
+	}
+	return versions[len(versions)-1]
+}
+
+func writeVaultJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeVaultError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	errs := []string{}
+	if msg != "" {
+		errs = append(errs, msg)
+	}
+	json.NewEncoder(w).Encode(map[string]any{"errors": errs})
+}
+
+// newTestVaultProvider creates a provider for the fake Vault server, authenticating with a token
+func newTestVaultProvider(t *testing.T, server *httptest.Server, kvVersion int) *vaultProvider {
+	provider, err := newVaultProvider(vaultConfig{
+		Address:   server.URL,
+		Mount:     "secret",
+		KVVersion: kvVersion,
+		Token:     "root-token",
+	}, server.Client())
+	if err != nil {
+		t.Fatalf("newVaultProvider() error = %v", err)
+	}
+	return provider
+}
+
+func TestParseVaultRef(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    vaultRef
+		wantErr bool
+	}{
+		{"myapp/prod/db", vaultRef{Path: "myapp/prod/db", Field: "value"}, false},
+		{"myapp/prod/db#password", vaultRef{Path: "myapp/prod/db", Field: "password"}, false},
+		{"myapp/prod/db?version=3#password", vaultRef{Path: "myapp/prod/db", Version: 3, Field: "password"}, false},
+		{"myapp/prod/db#", vaultRef{}, true},
+		{"myapp/prod/db?version=0", vaultRef{}, true},
+		{"myapp/prod/db?version=latest", vaultRef{}, true},
+		{"myapp/prod/db?stage=1", vaultRef{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := parseVaultRef(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVaultRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseVaultRef() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateVaultPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		kvVersion int
+		wantErr   bool
+	}{
+		{"Secret", "myapp/prod/db", 2, false},
+		{"Field", "myapp/prod/db#password", 2, false},
+		{"Version on KV v2", "myapp/prod/db?version=2#password", 2, false},
+		{"Version on KV v1", "myapp/prod/db?version=2#password", 1, true},
+		{"Leading slash", "/myapp/prod/db", 2, true},
+		{"Folder", "myapp/prod/", 2, true},
+		{"Traversal", "myapp/../db", 2, true},
+		{"Space", "myapp/prod db", 2, true},
+		{"Empty", "", 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVaultPath(tt.path, tt.kvVersion)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateVaultPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewVaultProviderValidatesConfig(t *testing.T) {
+	valid := vaultConfig{Address: "https://vault.example.com:8200", Mount: "secret", KVVersion: 2, Token: "t"}
+
+	tests := []struct {
+		name    string
+		modify  func(c *vaultConfig)
+		wantErr bool
+	}{
+		{"Valid", func(c *vaultConfig) {}, false},
+		{"AppRole", func(c *vaultConfig) { c.Token, c.RoleID, c.SecretID = "", "role", "secret" }, false},
+		{"No scheme", func(c *vaultConfig) { c.Address = "vault.example.com" }, true},
+		{"Bad KV version", func(c *vaultConfig) { c.KVVersion = 3 }, true},
+		{"Bad mount", func(c *vaultConfig) { c.Mount = "" }, true},
+		{"No credentials", func(c *vaultConfig) { c.Token = "" }, true},
+		{"Role ID without secret ID", func(c *vaultConfig) { c.Token, c.RoleID = "", "role" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.modify(&config)
+			_, err := newVaultProvider(config, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newVaultProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVaultProviderGet(t *testing.T) {
+	for _, kvVersion := range []int{1, 2} {
+		t.Run(fmt.Sprintf("KV v%d", kvVersion), func(t *testing.T) {
+			_, server := newFakeVault(t, kvVersion, map[string]map[string]any{
+				"myapp/prod/api-key": {"value": "my-api-key"},
+				"myapp/prod/db":      {"username": "app", "password": "s3cret", "port": 5432},
+			})
+			provider := newTestVaultProvider(t, server, kvVersion)
+			ctx := context.Background()
+
+			tests := map[string]string{
+				"myapp/prod/api-key":     "my-api-key",
+				"myapp/prod/db#password": "s3cret",
+				"myapp/prod/db#port":     "5432",
+			}
+			for path, want := range tests {
+				got, err := provider.Get(ctx, path)
+				if err != nil {
+					t.Fatalf("Get(%q) error = %v", path, err)
+				}
+				if got != want {
+					t.Errorf("Get(%q) = %q, want %q", path, got, want)
+				}
+			}
+
+			for _, path := range []string{"myapp/prod/missing", "myapp/prod/db#missing", "myapp/prod/db"} {
+				if _, err := provider.Get(ctx, path); !errors.Is(err, errSecretNotFound) {
+					t.Errorf("Get(%q) error = %v, want errSecretNotFound", path, err)
+				}
+			}
+		})
+	}
+}
+
+func TestVaultProviderVersions(t *testing.T) {
+	fake, server := newFakeVault(t, 2, map[string]map[string]any{
+		"myapp/prod/db": {"password": "v1"},
+	})
+	provider := newTestVaultProvider(t, server, 2)
+	ctx := context.Background()
+
+	if err := provider.Put(ctx, "myapp/prod/db#password", "v2"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if len(fake.versions["myapp/prod/db"]) != 2 {
+		t.Fatalf("Expected a second version, got %d", len(fake.versions["myapp/prod/db"]))
+	}
+
+	for path, want := range map[string]string{
+		"myapp/prod/db#password":           "v2",
+		"myapp/prod/db?version=1#password": "v1",
+		"myapp/prod/db?version=2#password": "v2",
+	} {
+		got, err := provider.Get(ctx, path)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", path, err)
+		}
+		if got != want {
+			t.Errorf("Get(%q) = %q, want %q", path, got, want)
+		}
+	}
+
+	if err := provider.Put(ctx, "myapp/prod/db?version=1#password", "x"); err == nil {
+		t.Error("Expected an error writing to a specific version")
+	}
+}
+
+func TestVaultProviderPutKeepsOtherFields(t *testing.T) {
+	for _, kvVersion := range []int{1, 2} {
+		t.Run(fmt.Sprintf("KV v%d", kvVersion), func(t *testing.T) {
+			fake, server := newFakeVault(t, kvVersion, map[string]map[string]any{
+				"myapp/prod/db": {"username": "app", "password": "old", "port": 5432},
+			})
+			provider := newTestVaultProvider(t, server, kvVersion)
+			ctx := context.Background()
+
+			if err := provider.Put(ctx, "myapp/prod/db#password", "new"); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			if err := provider.Put(ctx, "myapp/prod/new", "created"); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+
+			db := fake.latest("myapp/prod/db")
+			if db["username"] != "app" || db["password"] != "new" || fmt.Sprint(db["port"]) != "5432" {
+				t.Errorf("secret = %v, want only the password changed", db)
+			}
+			if created := fake.latest("myapp/prod/new"); created["value"] != "created" {
+				t.Errorf("new secret = %v, want value=created", created)
+			}
+		})
+	}
+}
+
+func TestVaultProviderPushWritesEachSecretOnce(t *testing.T) {
+	fake, server := newFakeVault(t, 2, map[string]map[string]any{
+		"myapp/prod/db": {"username": "app", "password": "old"},
+	})
+	provider := newTestVaultProvider(t, server, 2)
+
+	envVars := map[string]string{
+		"DB_USER":     "app",
+		"DB_PASSWORD": "new",
+		"DB_HOST":     "db.internal",
+		"DB_PORT":     "5432",
+		"API_KEY":     "my-api-key",
+	}
+	paramMap := ParameterMap{
+		"DB_USER":     "myapp/prod/db#username",
+		"DB_PASSWORD": "myapp/prod/db#password",
+		"DB_HOST":     "myapp/prod/db#host",
+		"DB_PORT":     "myapp/prod/db#port",
+		"API_KEY":     "myapp/prod/api-key",
+	}
+
+	changes, err := pushParameters(context.Background(), provider, envVars, paramMap, 4)
+	if err != nil {
+		t.Fatalf("pushParameters() error = %v", err)
+	}
+	if created, updated, unchanged := countPushChanges(changes); created != 3 || updated != 1 || unchanged != 1 {
+		t.Errorf("countPushChanges() = %d, %d, %d, want 3, 1, 1", created, updated, unchanged)
+	}
+
+	// Three fields changed, but the secret only gets one new version
+	if versions := len(fake.versions["myapp/prod/db"]); versions != 2 {
+		t.Errorf("Expected 2 versions of myapp/prod/db, got %d", versions)
+	}
+	if db := fake.latest("myapp/prod/db"); len(db) != 4 || db["password"] != "new" || db["port"] != "5432" {
+		t.Errorf("secret = %v", db)
+	}
+	if versions := len(fake.versions["myapp/prod/api-key"]); versions != 1 {
+		t.Errorf("Expected 1 version of myapp/prod/api-key, got %d", versions)
+	}
+}
+
+func TestVaultProviderFetchReadsEachSecretOnce(t *testing.T) {
+	fake, server := newFakeVault(t, 2, map[string]map[string]any{
+		"myapp/prod/db": {"username": "app", "password": "s3cret", "host": "db.internal"},
+	})
+	provider := newTestVaultProvider(t, server, 2)
+
+	paramMap := ParameterMap{
+		"DB_USER":     "myapp/prod/db#username",
+		"DB_PASSWORD": "myapp/prod/db#password",
+		"DB_HOST":     "myapp/prod/db#host",
+	}
+
+	envVars, err := fetchParameters(context.Background(), provider, paramMap, 1)
+	if err != nil {
+		t.Fatalf("fetchParameters() error = %v", err)
+	}
+
+	if fake.reads != 1 {
+		t.Errorf("Expected 1 read, got %d", fake.reads)
+	}
+	if envVars["DB_USER"] != "app" || envVars["DB_PASSWORD"] != "s3cret" || envVars["DB_HOST"] != "db.internal" {
+		t.Errorf("fetchParameters() = %v", envVars)
+	}
+}
+
+func TestVaultProviderAppRole(t *testing.T) {
+	fake, server := newFakeVault(t, 2, map[string]map[string]any{
+		"myapp/prod/api-key": {"value": "my-api-key"},
+	})
+
+	provider, err := newVaultProvider(vaultConfig{
+		Address:   server.URL,
+		Mount:     "secret",
+		KVVersion: 2,
+		RoleID:    "role",
+		SecretID:  "secret",
+	}, server.Client())
+	if err != nil {
+		t.Fatalf("newVaultProvider() error = %v", err)
+	}
+
+	paramMap := ParameterMap{"API_KEY": "myapp/prod/api-key", "OTHER": "myapp/prod/other"}
+	envVars, err := fetchParameters(context.Background(), provider, paramMap, 2)
+	if err != nil {
+		t.Fatalf("fetchParameters() error = %v", err)
+	}
+	if envVars["API_KEY"] != "my-api-key" {
+		t.Errorf("API_KEY = %q, want my-api-key", envVars["API_KEY"])
+	}
+	if fake.logins != 1 {
+		t.Errorf("Expected a single AppRole login, got %d", fake.logins)
+	}
+}
+
+func TestVaultProviderAuthErrors(t *testing.T) {
+	_, server := newFakeVault(t, 2, map[string]map[string]any{
+		"myapp/prod/api-key": {"value": "my-api-key"},
+	})
+
+	tests := []struct {
+		name   string
+		config vaultConfig
+	}{
+		{"Bad token", vaultConfig{Token: "wrong"}},
+		{"Bad AppRole secret", vaultConfig{RoleID: "role", SecretID: "wrong"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Address, config.Mount, config.KVVersion = server.URL, "secret", 2
+			provider, err := newVaultProvider(config, server.Client())
+			if err != nil {
+				t.Fatalf("newVaultProvider() error = %v", err)
+			}
+
+			_, err = provider.Get(context.Background(), "myapp/prod/api-key")
+			if !isAuthError(err) {
+				t.Errorf("Get() error = %v, want an auth error", err)
+			}
+		})
+	}
+}
+
+func TestVaultProviderList(t *testing.T) {
+	for _, kvVersion := range []int{1, 2} {
+		t.Run(fmt.Sprintf("KV v%d", kvVersion), func(t *testing.T) {
+			secrets := map[string]map[string]any{
+				"myapp/prod/db-password":  {"value": "a"},
+				"myapp/prod/api-key":      {"value": "b"},
+				"myapp/prod/database/url": {"value": "c"},
+				"myapp/test/db-password":  {"value": "d"},
+			}
+			_, server := newFakeVault(t, kvVersion, secrets)
+			provider := newTestVaultProvider(t, server, kvVersion)
+
+			tests := map[string][]string{
+				"myapp/prod/":    {"myapp/prod/api-key", "myapp/prod/database/url", "myapp/prod/db-password"},
+				"myapp/prod/db-": {"myapp/prod/db-password"},
+				"other/":         nil,
+			}
+			for prefix, want := range tests {
+				paths, err := provider.List(context.Background(), prefix)
+				if err != nil {
+					t.Fatalf("List(%q) error = %v", prefix, err)
+				}
+				if strings.Join(paths, ",") != strings.Join(want, ",") {
+					t.Errorf("List(%q) = %v, want %v", prefix, paths, want)
+				}
+			}
+		})
+	}
+}
+
+func TestVaultProviderDelete(t *testing.T) {
+	fake, server := newFakeVault(t, 2, map[string]map[string]any{
+		"myapp/prod/db":      {"username": "app", "password": "s3cret"},
+		"myapp/prod/api-key": {"value": "my-api-key"},
+	})
+	provider := newTestVaultProvider(t, server, 2)
+	ctx := context.Background()
+
+	if err := provider.Delete(ctx, "myapp/prod/db#password"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if db := fake.latest("myapp/prod/db"); len(db) != 1 || db["username"] != "app" {
+		t.Errorf("secret = %v, want only the username left", db)
+	}
+
+	if err := provider.Delete(ctx, "myapp/prod/api-key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := provider.Get(ctx, "myapp/prod/api-key"); !errors.Is(err, errSecretNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want errSecretNotFound", err)
+	}
+}
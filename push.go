@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Actions a push would take for a single key
+const (
+	pushCreate    = "create"
+	pushUpdate    = "update"
+	pushUnchanged = "unchanged"
+)
+
+// pushChange is the action a push would take for a single mapped key
+type pushChange struct {
+	Difference
+	Action string
+}
+
+// planPush compares the local values with the current remote values and returns the action
+// a push would take for every mapped key set in the .env file, sorted by key. Nothing is written.
+func planPush(ctx context.Context, provider SecretProvider, envVars map[string]string, paramMap ParameterMap, concurrency int) ([]pushChange, error) {
+	// Only fetch the keys there is something to push for
+	pushMap := make(ParameterMap)
+	for envKey, path := range paramMap {
+		if _, exists := envVars[envKey]; exists {
+			pushMap[envKey] = path
+		}
+	}
+
+	// Missing parameters are the ones the push creates, so they are not warned about
+	remoteEnvVars, err := fetchExistingParameters(ctx, provider, pushMap, concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch current %s parameters: %w", provider.Name(), err)
+	}
+
+	changes := make([]pushChange, 0, len(pushMap))
+	for envKey, path := range pushMap {
+		localVal := envVars[envKey]
+		remoteVal, remoteExists := remoteEnvVars[envKey]
+
+		action := pushUnchanged
+		switch {
+		case !remoteExists:
+			action = pushCreate
+		case localVal != remoteVal:
+			action = pushUpdate
+		}
+
+		changes = append(changes, pushChange{
+			Difference: Difference{
+				Key:          envKey,
+				LocalVal:     localVal,
+				RemoteVal:    remoteVal,
+				RemotePath:   path,
+				ExistsRemote: remoteExists,
+			},
+			Action: action,
+		})
+	}
+
+	// Sort changes by key for consistent output
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes, nil
+}
+
+// countPushChanges counts the planned changes per action
+func countPushChanges(changes []pushChange) (created, updated, unchanged int) {
+	for _, change := range changes {
+		switch change.Action {
+		case pushCreate:
+			created++
+		case pushUpdate:
+			updated++
+		default:
+			unchanged++
+		}
+	}
+	return created, updated, unchanged
+}
+
+// printPushPlan prints what a push would do for each key, masking values unless show is set
+func printPushPlan(changes []pushChange, remoteName string, show bool) {
+	fmt.Printf("Dry run: no changes will be made to %s\n\n", remoteName)
+
+	for _, change := range changes {
+		switch change.Action {
+		case pushCreate:
+			fmt.Printf("CREATE     %s (%s)\n", change.Key, change.RemotePath)
+			printDifferenceValues(change.Difference, remoteName, show)
+		case pushUpdate:
+			fmt.Printf("UPDATE     %s (%s)\n", change.Key, change.RemotePath)
+			printDifferenceValues(change.Difference, remoteName, show)
+		default:
+			fmt.Printf("UNCHANGED  %s (%s)\n", change.Key, change.RemotePath)
+		}
+	}
+
+	created, updated, unchanged := countPushChanges(changes)
+	fmt.Printf("\nDry run: %d to create, %d to update, %d unchanged in %s\n", created, updated, unchanged, remoteName)
+}
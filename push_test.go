@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPlanPush(t *testing.T) {
+	provider := newMemProvider(map[string]string{
+		"/myapp/dev/db-password": "remote_password",
+		"/myapp/dev/api-key":     "same_key",
+	})
+	envVars := map[string]string{
+		"DB_PASSWORD": "local_password",
+		"API_KEY":     "same_key",
+		"NEW_KEY":     "new_value",
+		"UNMAPPED":    "ignored",
+	}
+	paramMap := ParameterMap{
+		"DB_PASSWORD":  "/myapp/dev/db-password",
+		"API_KEY":      "/myapp/dev/api-key",
+		"NEW_KEY":      "/myapp/dev/new-key",
+		"DATABASE_URL": "/myapp/dev/database-url",
+	}
+
+	var status bytes.Buffer
+	statusOut = &status
+	defer func() { statusOut = os.Stdout }()
+
+	changes, err := planPush(context.Background(), provider, envVars, paramMap, 2)
+	if err != nil {
+		t.Fatalf("planPush() error = %v", err)
+	}
+
+	// NEW_KEY is about to be created, so not finding it is no reason for a warning
+	if strings.Contains(status.String(), "Warning") {
+		t.Errorf("Expected no warnings while planning, got %q", status.String())
+	}
+
+	expected := []struct{ key, action string }{
+		{"API_KEY", pushUnchanged},
+		{"DB_PASSWORD", pushUpdate},
+		{"NEW_KEY", pushCreate},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %+v", len(expected), len(changes), changes)
+	}
+	for i, want := range expected {
+		if changes[i].Key != want.key || changes[i].Action != want.action {
+			t.Errorf("Change %d: expected %s %s, got %s %s", i, want.action, want.key, changes[i].Action, changes[i].Key)
+		}
+	}
+
+	if len(provider.puts) != 0 {
+		t.Errorf("Expected no writes during planning, got %v", provider.puts)
+	}
+
+	created, updated, unchanged := countPushChanges(changes)
+	if created != 1 || updated != 1 || unchanged != 1 {
+		t.Errorf("Expected 1 created, 1 updated, 1 unchanged, got %d, %d, %d", created, updated, unchanged)
+	}
+}
+
+func TestPushParametersSkipsUnchanged(t *testing.T) {
+	provider := newMemProvider(map[string]string{
+		"/myapp/dev/db-password": "remote_password",
+		"/myapp/dev/api-key":     "same_key",
+	})
+	envVars := map[string]string{
+		"DB_PASSWORD": "local_password",
+		"API_KEY":     "same_key",
+		"NEW_KEY":     "new_value",
+	}
+	paramMap := ParameterMap{
+		"DB_PASSWORD": "/myapp/dev/db-password",
+		"API_KEY":     "/myapp/dev/api-key",
+		"NEW_KEY":     "/myapp/dev/new-key",
+	}
+
+	changes, err := pushParameters(context.Background(), provider, envVars, paramMap, 2)
+	if err != nil {
+		t.Fatalf("pushParameters() error = %v", err)
+	}
+
+	if len(provider.puts) != 2 || provider.puts[0] != "/myapp/dev/db-password" || provider.puts[1] != "/myapp/dev/new-key" {
+		t.Errorf("Expected only DB_PASSWORD and NEW_KEY to be written, got %v", provider.puts)
+	}
+
+	created, updated, unchanged := countPushChanges(changes)
+	if created != 1 || updated != 1 || unchanged != 1 {
+		t.Errorf("Expected 1 created, 1 updated, 1 unchanged, got %d, %d, %d", created, updated, unchanged)
+	}
+}
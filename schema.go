@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParameterSpec holds the settings of a mapping entry written in object form, e.g.
+// {"path": "/app/db", "required": true, "type": "url", "description": "Primary database"}
+type ParameterSpec struct {
+	Path        string
+	Required    bool
+	Default     *string
+	Description string
+	Type        string
+}
+
+// ParameterSpecs holds the specs of the object-form entries of a mapping, keyed by env var name
+type ParameterSpecs map[string]ParameterSpec
+
+// valueTypes are the value types a mapping entry can declare, with their validators
+var valueTypes = map[string]func(value string) error{
+	"string": func(value string) error { return nil },
+	"int": func(value string) error {
+		_, err := strconv.Atoi(value)
+		return err
+	},
+	"number": func(value string) error {
+		_, err := strconv.ParseFloat(value, 64)
+		return err
+	},
+	"bool": func(value string) error {
+		_, err := strconv.ParseBool(value)
+		return err
+	},
+	"url": func(value string) error {
+		u, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("missing scheme or host")
+		}
+		return nil
+	},
+	"json": func(value string) error {
+		if !json.Valid([]byte(value)) {
+			return fmt.Errorf("invalid JSON")
+		}
+		return nil
+	},
+}
+
+// parseMapEntries splits decoded mapping entries into the path map and the specs of the
+// entries written in object form. Entries in plain string form are paths.
+func parseMapEntries(entries map[string]any) (ParameterMap, ParameterSpecs, error) {
+	paramMap := make(ParameterMap, len(entries))
+	specs := make(ParameterSpecs)
+
+	for _, envKey := range sortedKeys(entries) {
+		switch entry := entries[envKey].(type) {
+		case string:
+			paramMap[envKey] = entry
+		case map[string]any:
+			if envKey == prefixMapKey {
+				return nil, nil, fmt.Errorf("prefix entry %q must be a string", prefixMapKey)
+			}
+			spec, err := parseParameterSpec(entry)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid entry for %s: %w", envKey, err)
+			}
+			paramMap[envKey] = spec.Path
+			specs[envKey] = spec
+		default:
+			return nil, nil, fmt.Errorf("invalid entry for %s: must be a path or an object", envKey)
+		}
+	}
+
+	return paramMap, specs, nil
+}
+
+// parseParameterSpec parses a mapping entry written in object form
+func parseParameterSpec(entry map[string]any) (ParameterSpec, error) {
+	var spec ParameterSpec
+	for _, field := range sortedKeys(entry) {
+		value := entry[field]
+		var ok bool
+		switch field {
+		case "path":
+			spec.Path, ok = value.(string)
+		case "required":
+			spec.Required, ok = value.(bool)
+		case "default":
+			var def string
+			def, ok = scalarString(value)
+			spec.Default = &def
+		case "description":
+			spec.Description, ok = value.(string)
+		case "type":
+			spec.Type, ok = value.(string)
+		default:
+			return spec, fmt.Errorf("unknown field %q", field)
+		}
+		if !ok {
+			return spec, fmt.Errorf("invalid value for field %q", field)
+		}
+	}
+
+	if spec.Path == "" {
+		return spec, fmt.Errorf("missing path")
+	}
+	if spec.Required && spec.Default != nil {
+		return spec, fmt.Errorf("a required entry cannot have a default")
+	}
+	if spec.Type != "" {
+		if _, known := valueTypes[spec.Type]; !known {
+			return spec, fmt.Errorf("unknown type %q (expected one of %s)", spec.Type, strings.Join(sortedKeys(valueTypes), ", "))
+		}
+		if spec.Default != nil {
+			if err := checkValueType(*spec.Default, spec.Type); err != nil {
+				return spec, fmt.Errorf("default does not match type: %w", err)
+			}
+		}
+	}
+
+	return spec, nil
+}
+
+// scalarString formats a string, number or boolean mapping value as a string
+func scalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool, json.Number, int, int64, uint64, float64:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// checkValueType checks a value against a declared type without including the value in the error
+func checkValueType(value, valueType string) error {
+	validate, known := valueTypes[valueType]
+	if !known {
+		return fmt.Errorf("unknown type %q", valueType)
+	}
+	if err := validate(value); err != nil {
+		return fmt.Errorf("not a valid %s", valueType)
+	}
+	return nil
+}
+
+// applyDefaults fills in the defaults of optional keys missing from envVars, without checking
+// required keys or types, so check and sync see the same values a pull would write
+func applyDefaults(envVars map[string]string, specs ParameterSpecs) {
+	for envKey, spec := range specs {
+		if _, exists := envVars[envKey]; !exists && spec.Default != nil {
+			envVars[envKey] = *spec.Default
+		}
+	}
+}
+
+// applyParameterSpecs fills in defaults for optional keys missing from envVars and checks
+// required keys and declared types, reporting every problem at once
+func applyParameterSpecs(envVars map[string]string, specs ParameterSpecs) error {
+	var missing, invalid []string
+	for _, envKey := range sortedKeys(specs) {
+		spec := specs[envKey]
+
+		value, exists := envVars[envKey]
+		if !exists {
+			switch {
+			case spec.Required:
+				missing = append(missing, describeKey(envKey, spec))
+			case spec.Default != nil:
+				fmt.Fprintf(statusOut, "Using default value for %s.\n", envKey)
+				envVars[envKey] = *spec.Default
+			}
+			continue
+		}
+
+		if spec.Type != "" {
+			if err := checkValueType(value, spec.Type); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s: %v", envKey, err))
+			}
+		}
+	}
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("required parameter(s) not found: %s", strings.Join(missing, ", ")))
+	}
+	if len(invalid) > 0 {
+		problems = append(problems, fmt.Sprintf("parameter(s) with invalid values: %s", strings.Join(invalid, ", ")))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// describeKey names a key together with its description, if it has one
+func describeKey(envKey string, spec ParameterSpec) string {
+	if spec.Description == "" {
+		return envKey
+	}
+	return fmt.Sprintf("%s (%s)", envKey, spec.Description)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadParameterMapObjectEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	mapFile := filepath.Join(tmpDir, "test-map.json")
+
+	content := `{
+		"API_KEY": "/myapp/dev/api-key",
+		"DB_PASSWORD": {"path": "/myapp/dev/db-password", "required": true, "description": "Primary database password"},
+		"DB_PORT": {"path": "/myapp/dev/db-port", "default": 5432, "type": "int"}
+	}`
+	if err := os.WriteFile(mapFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	paramMap, specs, err := loadParameterMap(mapFile, &ssmProvider{}, nil)
+	if err != nil {
+		t.Fatalf("loadParameterMap() error = %v", err)
+	}
+
+	expected := ParameterMap{
+		"API_KEY":     "/myapp/dev/api-key",
+		"DB_PASSWORD": "/myapp/dev/db-password",
+		"DB_PORT":     "/myapp/dev/db-port",
+	}
+	for key, want := range expected {
+		if paramMap[key] != want {
+			t.Errorf("For key %s, expected path %q, got %q", key, want, paramMap[key])
+		}
+	}
+
+	if _, exists := specs["API_KEY"]; exists {
+		t.Errorf("Expected no spec for string entry API_KEY")
+	}
+	if !specs["DB_PASSWORD"].Required || specs["DB_PASSWORD"].Description != "Primary database password" {
+		t.Errorf("Unexpected spec for DB_PASSWORD: %+v", specs["DB_PASSWORD"])
+	}
+	if def := specs["DB_PORT"].Default; def == nil || *def != "5432" || specs["DB_PORT"].Type != "int" {
+		t.Errorf("Unexpected spec for DB_PORT: %+v", specs["DB_PORT"])
+	}
+}
+
+func TestParseMapEntriesInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry any
+	}{
+		{"missing path", map[string]any{"required": true}},
+		{"unknown field", map[string]any{"path": "/myapp/dev/key", "requried": true}},
+		{"wrong field type", map[string]any{"path": "/myapp/dev/key", "required": "yes"}},
+		{"required with default", map[string]any{"path": "/myapp/dev/key", "required": true, "default": "x"}},
+		{"unknown type", map[string]any{"path": "/myapp/dev/key", "type": "email"}},
+		{"default not matching type", map[string]any{"path": "/myapp/dev/key", "type": "int", "default": "abc"}},
+		{"not a path or object", []any{"/myapp/dev/key"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := parseMapEntries(map[string]any{"KEY": tt.entry}); err == nil {
+				t.Errorf("Expected error for %s, got nil", tt.name)
+			}
+		})
+	}
+}
+
+func TestApplyParameterSpecs(t *testing.T) {
+	port := "5432"
+	specs := ParameterSpecs{
+		"DB_PORT":      {Path: "/myapp/dev/db-port", Default: &port, Type: "int"},
+		"DATABASE_URL": {Path: "/myapp/dev/database-url", Type: "url"},
+		"FEATURE_FLAG": {Path: "/myapp/dev/feature-flag"},
+	}
+	envVars := map[string]string{"DATABASE_URL": "postgresql://localhost:5432/mydb"}
+
+	if err := applyParameterSpecs(envVars, specs); err != nil {
+		t.Fatalf("applyParameterSpecs() error = %v", err)
+	}
+
+	if envVars["DB_PORT"] != "5432" {
+		t.Errorf("Expected default DB_PORT=5432, got %q", envVars["DB_PORT"])
+	}
+	if _, exists := envVars["FEATURE_FLAG"]; exists {
+		t.Errorf("Expected optional FEATURE_FLAG without default to stay unset")
+	}
+}
+
+func TestApplyParameterSpecsFailures(t *testing.T) {
+	specs := ParameterSpecs{
+		"DB_PASSWORD": {Path: "/myapp/dev/db-password", Required: true, Description: "Primary database password"},
+		"DB_PORT":     {Path: "/myapp/dev/db-port", Type: "int"},
+	}
+	envVars := map[string]string{"DB_PORT": "not-a-port"}
+
+	err := applyParameterSpecs(envVars, specs)
+	if err == nil {
+		t.Fatal("Expected error from applyParameterSpecs, got nil")
+	}
+	for _, want := range []string{"DB_PASSWORD (Primary database password)", "DB_PORT: not a valid int"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %q, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "not-a-port") {
+		t.Errorf("Error should not expose the value: %v", err)
+	}
+}
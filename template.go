@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+)
+
+// templateFuncs are the helper functions available to render templates
+var templateFuncs = template.FuncMap{
+	// quote returns a double-quoted string with Go escaping, e.g. for JSON-like or .properties files
+	"quote": strconv.Quote,
+
+	// json encodes a value as JSON, e.g. {{ json .API_KEY }} or {{ json . }}
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	},
+
+	// base64 encodes a string with standard base64
+	"base64": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+
+	// required fails the render with msg when the value is empty, e.g. {{ required "DB_PASSWORD is required" .DB_PASSWORD }}
+	"required": func(msg string, v any) (any, error) {
+		if v == nil || v == "" {
+			return nil, errors.New(msg)
+		}
+		return v, nil
+	},
+}
+
+// renderTemplateFile executes a Go text/template with the parameters as its data, so
+// {{ .DB_PASSWORD }} is replaced by the value of DB_PASSWORD. Referencing a key that was not
+// fetched is an error; use {{ index . "KEY" }} for optional keys.
+func renderTemplateFile(filename string, envVars map[string]string) ([]byte, error) {
+	// Validate filename to prevent path traversal
+	if err := validateFilePath(filename); err != nil {
+		return nil, fmt.Errorf("invalid file path: %w", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(filename)).Option("missingkey=error").Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, envVars); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplateFile(t *testing.T) {
+	templateFile := filepath.Join(t.TempDir(), "appsettings.json.tmpl")
+	content := `{
+  "ConnectionString": {{ json .DATABASE_URL }},
+  "ApiKey": {{ quote (required "API_KEY is required" .API_KEY) }},
+  "Cert": "{{ base64 .CERT }}",
+  "Optional": {{ json (index . "MISSING") }}
+}`
+	if err := os.WriteFile(templateFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+
+	envVars := map[string]string{
+		"DATABASE_URL": `Server=db;Password="p@ss"`,
+		"API_KEY":      "my-api-key",
+		"CERT":         "cert",
+	}
+
+	got, err := renderTemplateFile(templateFile, envVars)
+	if err != nil {
+		t.Fatalf("renderTemplateFile() error = %v", err)
+	}
+
+	want := `{
+  "ConnectionString": "Server=db;Password=\"p@ss\"",
+  "ApiKey": "my-api-key",
+  "Cert": "Y2VydA==",
+  "Optional": ""
+}`
+	if string(got) != want {
+		t.Errorf("Unexpected render output:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderTemplateFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"required", `{{ required "API_KEY is required" .API_KEY }}`, "API_KEY is required"},
+		{"missing key", `{{ .TYPO }}`, "TYPO"},
+		{"parse error", "line1\n{{ .API_KEY ", "failed to parse template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateFile := filepath.Join(t.TempDir(), "config.tmpl")
+			if err := os.WriteFile(templateFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create template: %v", err)
+			}
+
+			_, err := renderTemplateFile(templateFile, map[string]string{"API_KEY": ""})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
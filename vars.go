@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// varFlags collects repeated --var name=value flags
+type varFlags map[string]string
+
+func (v varFlags) String() string {
+	pairs := make([]string, 0, len(v))
+	for _, name := range sortedKeys(v) {
+		pairs = append(pairs, name+"="+v[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v varFlags) Set(value string) error {
+	name, val, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected name=value")
+	}
+	if err := validateVarName(name); err != nil {
+		return err
+	}
+	v[name] = val
+	return nil
+}
+
+// validateVarName validates the name of a mapping variable
+func validateVarName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty variable name")
+	}
+	for _, char := range name {
+		if !((char >= 'A' && char <= 'Z') || (char >= 'a' && char <= 'z') ||
+			(char >= '0' && char <= '9') || char == '_') {
+			return fmt.Errorf("variable name contains invalid character: %c", char)
+		}
+	}
+	return nil
+}
+
+// loadMapVars collects the variables available to ${name} placeholders in mapping paths.
+// Environment variables are overridden by the vars file, which is overridden by --var flags.
+func loadMapVars(flagVars map[string]string, varsFile string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if name, value, ok := strings.Cut(entry, "="); ok {
+			vars[name] = value
+		}
+	}
+
+	if varsFile != "" {
+		fileVars, err := readEnvFile(varsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vars file: %w", err)
+		}
+		for name, value := range fileVars {
+			if err := validateVarName(name); err != nil {
+				return nil, fmt.Errorf("invalid variable %q in vars file: %w", name, err)
+			}
+			vars[name] = value
+		}
+	}
+
+	for name, value := range flagVars {
+		vars[name] = value
+	}
+
+	return vars, nil
+}
+
+// interpolate replaces ${name} placeholders in s with their values, returning the names of
+// any placeholders that have no value
+func interpolate(s string, vars map[string]string) (string, []string, error) {
+	var b strings.Builder
+	var unresolved []string
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			break
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", nil, fmt.Errorf("unterminated placeholder")
+		}
+		name := s[start+2 : start+end]
+		if err := validateVarName(name); err != nil {
+			return "", nil, fmt.Errorf("invalid placeholder ${%s}: %w", name, err)
+		}
+
+		b.WriteString(s[:start])
+		if value, ok := vars[name]; ok {
+			b.WriteString(value)
+		} else {
+			unresolved = append(unresolved, name)
+		}
+		s = s[start+end+1:]
+	}
+
+	return b.String(), unresolved, nil
+}
+
+// interpolateParameterMap fills in the ${name} placeholders of every path in the map. All
+// unresolved placeholders are reported together so nothing is fetched from a half-filled path.
+func interpolateParameterMap(paramMap ParameterMap, vars map[string]string) (ParameterMap, error) {
+	interpolated := make(ParameterMap, len(paramMap))
+	unresolved := make(map[string][]string)
+	for _, envKey := range sortedKeys(paramMap) {
+		path, missing, err := interpolate(paramMap[envKey], vars)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path for key %q: %w", envKey, err)
+		}
+		for _, name := range missing {
+			unresolved[name] = append(unresolved[name], envKey)
+		}
+		interpolated[envKey] = path
+	}
+
+	if len(unresolved) > 0 {
+		var problems []string
+		for _, name := range sortedKeys(unresolved) {
+			problems = append(problems, fmt.Sprintf("${%s} (used by %s)", name, strings.Join(unresolved[name], ", ")))
+		}
+		return nil, fmt.Errorf("unresolved variable(s): %s; set them with --var, --vars-file or the environment", strings.Join(problems, ", "))
+	}
+
+	return interpolated, nil
+}
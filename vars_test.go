@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	vars := map[string]string{"stage": "prod", "app": "myapp"}
+
+	tests := []struct {
+		in             string
+		want           string
+		wantUnresolved []string
+		wantErr        bool
+	}{
+		{"/myapp/prod/db-password", "/myapp/prod/db-password", nil, false},
+		{"/myapp/${stage}/db-password", "/myapp/prod/db-password", nil, false},
+		{"/${app}/${stage}/db-password", "/myapp/prod/db-password", nil, false},
+		{"/myapp/${region}/db-password", "/myapp//db-password", []string{"region"}, false},
+		{"/myapp/${stage/db-password", "", nil, true},
+		{"/myapp/${st-age}/db-password", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, unresolved, err := interpolate(tt.in, vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("interpolate(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("interpolate(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if strings.Join(unresolved, ",") != strings.Join(tt.wantUnresolved, ",") {
+				t.Errorf("interpolate(%q) unresolved = %v, want %v", tt.in, unresolved, tt.wantUnresolved)
+			}
+		})
+	}
+}
+
+func TestLoadParameterMapInterpolation(t *testing.T) {
+	mapFile := filepath.Join(t.TempDir(), "map.json")
+	content := `{
+		"DB_PASSWORD": "/myapp/${stage}/db-password",
+		"DB_PORT": {"path": "/myapp/${stage}/db-port", "type": "int"}
+	}`
+	if err := os.WriteFile(mapFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	paramMap, specs, err := loadParameterMap(mapFile, &ssmProvider{}, map[string]string{"stage": "prod"})
+	if err != nil {
+		t.Fatalf("loadParameterMap() error = %v", err)
+	}
+	if paramMap["DB_PASSWORD"] != "/myapp/prod/db-password" || paramMap["DB_PORT"] != "/myapp/prod/db-port" {
+		t.Errorf("Unexpected parameter map: %v", paramMap)
+	}
+	if specs["DB_PORT"].Path != "/myapp/prod/db-port" {
+		t.Errorf("Expected spec path to be interpolated, got %q", specs["DB_PORT"].Path)
+	}
+
+	_, _, err = loadParameterMap(mapFile, &ssmProvider{}, nil)
+	if err == nil {
+		t.Fatal("Expected error for unresolved placeholder, got nil")
+	}
+	if !strings.Contains(err.Error(), "${stage} (used by DB_PASSWORD, DB_PORT)") {
+		t.Errorf("Expected error to name the variable and its keys, got %v", err)
+	}
+}
+
+func TestLoadMapVarsPrecedence(t *testing.T) {
+	t.Setenv("stage", "env")
+	t.Setenv("region", "eu-west-1")
+
+	varsFile := filepath.Join(t.TempDir(), "vars.env")
+	if err := os.WriteFile(varsFile, []byte("stage=file\napp=myapp\n"), 0644); err != nil {
+		t.Fatalf("Failed to create vars file: %v", err)
+	}
+
+	flagVars := varFlags{}
+	if err := flagVars.Set("app=override"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	vars, err := loadMapVars(flagVars, varsFile)
+	if err != nil {
+		t.Fatalf("loadMapVars() error = %v", err)
+	}
+
+	expected := map[string]string{"stage": "file", "region": "eu-west-1", "app": "override"}
+	for name, want := range expected {
+		if vars[name] != want {
+			t.Errorf("For variable %s, expected %q, got %q", name, want, vars[name])
+		}
+	}
+}
+
+func TestVarFlagsSetInvalid(t *testing.T) {
+	for _, value := range []string{"stage", "=prod", "st-age=prod"} {
+		if err := (varFlags{}).Set(value); err == nil {
+			t.Errorf("Expected error for --var %q, got nil", value)
+		}
+	}
+}